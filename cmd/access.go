@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// workspaceAccessCmd represents the workspace access command.
+var workspaceAccessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Manage team access to TFE workspaces",
+	Long:  `Manage team access to TFE workspaces.`,
+}
+
+var workspaceAccessListCmd = &cobra.Command{
+	Use:   "list [WORKSPACE]",
+	Short: "List the teams having access to a workspace",
+	Long:  `List the teams having access to a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Index the teams by ID to resolve their names.
+		teams, err := listTeams(client, organization)
+		if err != nil {
+			log.Fatalf("Cannot list the teams for %q: %s.", organization, err)
+		}
+		teamNames := map[string]string{}
+		for _, t := range teams {
+			teamNames[t.ID] = t.Name
+		}
+
+		// List the team accesses.
+		accesses, err := listTeamAccesses(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot list the team accesses for %q: %s.", name, err)
+		}
+
+		// Print the accesses.
+		for _, a := range accesses {
+			if a.Access != tfe.AccessCustom {
+				fmt.Printf("%s: %s\n", teamNames[a.Team.ID], a.Access)
+				continue
+			}
+			fmt.Printf(
+				"%s: %s (runs=%s, variables=%s, state-versions=%s, sentinel-mocks=%s, run-tasks=%t, workspace-locking=%t)\n",
+				teamNames[a.Team.ID], a.Access, a.Runs, a.Variables, a.StateVersions, a.SentinelMocks, a.RunTasks, a.WorkspaceLocking,
+			)
+		}
+	},
+}
+
+var workspaceAccessGrantCmd = &cobra.Command{
+	Use:   "grant [WORKSPACE]",
+	Short: "Grant a team access to a workspace",
+	Long: `Grant a team access to a workspace.
+
+The custom permission flags can only be used with "--access custom".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		teamName, _ := cmd.Flags().GetString("team")
+		rawAccess, _ := cmd.Flags().GetString("access")
+		force, _ := cmd.Flags().GetBool("force")
+
+		// Validate the access level.
+		access, err := tfecli.ParseAccessType(rawAccess)
+		if err != nil {
+			log.Fatalf("Cannot grant access: %s.", err)
+		}
+
+		// Validate the custom permissions.
+		permissions, err := readCustomPermissions(cmd, access)
+		if err != nil {
+			log.Fatalf("Cannot grant access: %s.", err)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Retrieve the team.
+		team, err := readTeam(client, organization, teamName)
+		if err != nil {
+			log.Fatalf("Cannot retrieve team %q: %s.", teamName, err)
+		}
+
+		// List existing accesses and index them by team ID.
+		indexedAccesses, err := indexTeamAccesses(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot index team accesses: %s.", err)
+		}
+
+		// Check if it exists.
+		teamAccess, exists := indexedAccesses[team.ID]
+
+		if exists {
+			if !force {
+				log.Fatalf("Cannot grant access to %q: team %q already has %q access.", name, teamName, teamAccess.Access)
+			}
+
+			// Update the access.
+			permissions.Access = &access
+			if _, err := client.TeamAccess.Update(context.Background(), teamAccess.ID, permissions); err != nil {
+				log.Fatalf("Cannot update the access of team %q to %q: %s.", teamName, name, err)
+			}
+			log.Infof("Team %q access to %q updated to %q.", teamName, name, access)
+			return
+		}
+
+		// Grant the access.
+		options := tfe.TeamAccessAddOptions{
+			Access:           &access,
+			Runs:             permissions.Runs,
+			Variables:        permissions.Variables,
+			StateVersions:    permissions.StateVersions,
+			SentinelMocks:    permissions.SentinelMocks,
+			WorkspaceLocking: permissions.WorkspaceLocking,
+			RunTasks:         permissions.RunTasks,
+			Team:             team,
+			Workspace:        workspace,
+		}
+		if _, err := client.TeamAccess.Add(context.Background(), options); err != nil {
+			log.Fatalf("Cannot grant team %q access to %q: %s.", teamName, name, err)
+		}
+		log.Infof("Team %q granted %q access to %q.", teamName, access, name)
+	},
+}
+
+var workspaceAccessRevokeCmd = &cobra.Command{
+	Use:   "revoke [WORKSPACE]",
+	Short: "Revoke the access of a team to a workspace",
+	Long:  `Revoke the access of a team to a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		teamName, _ := cmd.Flags().GetString("team")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Retrieve the team.
+		team, err := readTeam(client, organization, teamName)
+		if err != nil {
+			log.Fatalf("Cannot retrieve team %q: %s.", teamName, err)
+		}
+
+		// List existing accesses and index them by team ID.
+		indexedAccesses, err := indexTeamAccesses(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot index team accesses: %s.", err)
+		}
+
+		// Check if it exists.
+		teamAccess, exists := indexedAccesses[team.ID]
+		if !exists {
+			log.Warningf("Cannot revoke access: team %q has no access to %q.", teamName, name)
+			return
+		}
+
+		// Revoke it.
+		if err := client.TeamAccess.Remove(context.Background(), teamAccess.ID); err != nil {
+			log.Fatalf("Cannot revoke team %q access to %q: %s.", teamName, name, err)
+		}
+		log.Infof("Team %q access to %q revoked successfully.", teamName, name)
+	},
+}
+
+func init() {
+	workspaceCmd.AddCommand(workspaceAccessCmd)
+	workspaceAccessCmd.AddCommand(workspaceAccessListCmd)
+	workspaceAccessCmd.AddCommand(workspaceAccessGrantCmd)
+	workspaceAccessCmd.AddCommand(workspaceAccessRevokeCmd)
+
+	workspaceAccessGrantCmd.Flags().String("team", "", "Specify the team name")
+	workspaceAccessGrantCmd.Flags().String("access", "", "Specify the access level (read, plan, write, admin, custom)")
+	workspaceAccessGrantCmd.Flags().String("runs", "", "Specify the custom runs permission (read, plan, apply)")
+	workspaceAccessGrantCmd.Flags().String("variables", "", "Specify the custom variables permission (none, read, write)")
+	workspaceAccessGrantCmd.Flags().String("state-versions", "", "Specify the custom state versions permission (none, read-outputs, read, write)")
+	workspaceAccessGrantCmd.Flags().String("sentinel-mocks", "", "Specify the custom Sentinel mocks permission (none, read)")
+	workspaceAccessGrantCmd.Flags().Bool("run-tasks", false, "Allow the team to manage run tasks (custom access only)")
+	workspaceAccessGrantCmd.Flags().Bool("workspace-locking", false, "Allow the team to lock the workspace (custom access only)")
+	workspaceAccessGrantCmd.Flags().BoolP("force", "f", false, "Update the team access if it exists")
+	_ = workspaceAccessGrantCmd.MarkFlagRequired("team")
+	_ = workspaceAccessGrantCmd.MarkFlagRequired("access")
+
+	workspaceAccessRevokeCmd.Flags().String("team", "", "Specify the team name")
+	_ = workspaceAccessRevokeCmd.MarkFlagRequired("team")
+}
+
+// readCustomPermissions reads and validates the custom permission flags.
+func readCustomPermissions(cmd *cobra.Command, access tfe.AccessType) (tfe.TeamAccessUpdateOptions, error) {
+	options := tfe.TeamAccessUpdateOptions{}
+	flags := []string{"runs", "variables", "state-versions", "sentinel-mocks", "run-tasks", "workspace-locking"}
+
+	// The custom permission flags are rejected unless the access is custom.
+	if access != tfe.AccessCustom {
+		for _, f := range flags {
+			if cmd.Flags().Changed(f) {
+				return options, fmt.Errorf("--%s requires --access custom", f)
+			}
+		}
+		return options, nil
+	}
+
+	if cmd.Flags().Changed("runs") {
+		runs, _ := cmd.Flags().GetString("runs")
+		if err := tfecli.ValidatePermission("runs", runs, "read", "plan", "apply"); err != nil {
+			return options, err
+		}
+		options.Runs = tfe.RunsPermission(tfe.RunsPermissionType(runs))
+	}
+	if cmd.Flags().Changed("variables") {
+		variables, _ := cmd.Flags().GetString("variables")
+		if err := tfecli.ValidatePermission("variables", variables, "none", "read", "write"); err != nil {
+			return options, err
+		}
+		options.Variables = tfe.VariablesPermission(tfe.VariablesPermissionType(variables))
+	}
+	if cmd.Flags().Changed("state-versions") {
+		stateVersions, _ := cmd.Flags().GetString("state-versions")
+		if err := tfecli.ValidatePermission("state-versions", stateVersions, "none", "read-outputs", "read", "write"); err != nil {
+			return options, err
+		}
+		options.StateVersions = tfe.StateVersionsPermission(tfe.StateVersionsPermissionType(stateVersions))
+	}
+	if cmd.Flags().Changed("sentinel-mocks") {
+		sentinelMocks, _ := cmd.Flags().GetString("sentinel-mocks")
+		if err := tfecli.ValidatePermission("sentinel-mocks", sentinelMocks, "none", "read"); err != nil {
+			return options, err
+		}
+		options.SentinelMocks = tfe.SentinelMocksPermission(tfe.SentinelMocksPermissionType(sentinelMocks))
+	}
+	if cmd.Flags().Changed("run-tasks") {
+		runTasks, _ := cmd.Flags().GetBool("run-tasks")
+		options.RunTasks = tfe.Bool(runTasks)
+	}
+	if cmd.Flags().Changed("workspace-locking") {
+		locking, _ := cmd.Flags().GetBool("workspace-locking")
+		options.WorkspaceLocking = tfe.Bool(locking)
+	}
+
+	return options, nil
+}
+
+func listTeams(client *tfe.Client, organization string) ([]*tfe.Team, error) {
+	results := []*tfe.Team{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.TeamListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			}}
+		t, err := client.Teams.List(context.Background(), organization, &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, t.Items...)
+
+		// Check if there is another poage to retrieve.
+		if t.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+func readTeam(client *tfe.Client, organization, name string) (*tfe.Team, error) {
+	options := tfe.TeamListOptions{Names: []string{name}}
+	t, err := client.Teams.List(context.Background(), organization, &options)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range t.Items {
+		if team.Name == name {
+			return team, nil
+		}
+	}
+	return nil, fmt.Errorf("team not found")
+}
+
+func listTeamAccesses(client *tfe.Client, workspaceID string) ([]*tfe.TeamAccess, error) {
+	results := []*tfe.TeamAccess{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.TeamAccessListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			},
+			WorkspaceID: workspaceID,
+		}
+		a, err := client.TeamAccess.List(context.Background(), &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, a.Items...)
+
+		// Check if there is another poage to retrieve.
+		if a.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+func indexTeamAccesses(client *tfe.Client, workspaceID string) (map[string]*tfe.TeamAccess, error) {
+	// List existing accesses.
+	accesses, err := listTeamAccesses(client, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the team accesses for %q: %s", workspaceID, err)
+	}
+
+	// Index them by team ID.
+	indexedAccesses := map[string]*tfe.TeamAccess{}
+	for _, a := range accesses {
+		indexedAccesses[a.Team.ID] = a
+	}
+
+	return indexedAccesses, nil
+}
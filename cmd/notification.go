@@ -78,10 +78,14 @@ var notificationCreateCmd = &cobra.Command{
 		destinationType, _ := cmd.Flags().GetString("type")
 		disabled, _ := cmd.Flags().GetBool("disabled")
 		token, _ := cmd.Flags().GetString("token")
-		triggers, _ := cmd.Flags().GetStringArray("triggers")
+		rawTriggers, _ := cmd.Flags().GetStringArray("triggers")
 		url, _ := cmd.Flags().GetString("url")
 		emailAddresses, _ := cmd.Flags().GetStringArray("emailaddresses")
 		force, _ := cmd.Flags().GetBool("force")
+		triggers := []tfe.NotificationTriggerType{}
+		for _, t := range rawTriggers {
+			triggers = append(triggers, tfe.NotificationTriggerType(t))
+		}
 
 		// Setup the command.
 		organization, client, err := tfecli.Setup(cmd)
@@ -202,7 +206,7 @@ func listNotifications(client *tfe.Client, workspaceID string) ([]*tfe.Notificat
 			ListOptions: tfe.ListOptions{
 				PageNumber: currentPage,
 			}}
-		v, err := client.NotificationConfigurations.List(context.Background(), workspaceID, options)
+		v, err := client.NotificationConfigurations.List(context.Background(), workspaceID, &options)
 		if err != nil {
 			return nil, err
 		}
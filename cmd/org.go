@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// orgCmd represents the org command.
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Inspect a TFE organization",
+	Long:  `Inspect a TFE organization.`,
+}
+
+var orgGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the dependency graph of the workspaces",
+	Long: `Export the dependency graph of the workspaces.
+
+Workspaces are connected by their run triggers and by their remote state consumers.
+Workspaces sharing their state with the whole organization are flagged but not
+connected to every other workspace.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		format, _ := cmd.Flags().GetString("format")
+		from, _ := cmd.Flags().GetString("from")
+
+		// Select the renderer.
+		var render func(*tfecli.Graph) error
+		switch format {
+		case "dot":
+			render = func(g *tfecli.Graph) error { return g.WriteDOT(os.Stdout) }
+		case "mermaid":
+			render = func(g *tfecli.Graph) error { return g.WriteMermaid(os.Stdout) }
+		case "json":
+			render = func(g *tfecli.Graph) error { return g.WriteJSON(os.Stdout) }
+		default:
+			log.Fatalf("Invalid format %q: must be one of dot, mermaid or json.", format)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// List workspaces.
+		workspaces, err := listWorkspaces(client, organization)
+		if err != nil {
+			log.Fatalf("Cannot list the workspaces for  %q: %s.", organization, err)
+		}
+
+		// Build the graph.
+		graph, err := buildWorkspaceGraph(client, workspaces)
+		if err != nil {
+			log.Fatalf("Cannot build the workspace graph: %s.", err)
+		}
+
+		// Keep only the downstream workspaces if needed.
+		if from != "" {
+			graph, err = graph.Downstream(from)
+			if err != nil {
+				log.Fatalf("Cannot compute the downstream workspaces: %s.", err)
+			}
+		}
+
+		// Report the cycles.
+		for _, cycle := range graph.Cycles() {
+			log.Warningf("Dependency cycle detected: %s.", strings.Join(cycle, ", "))
+		}
+
+		// Print the graph.
+		if err := render(graph); err != nil {
+			log.Fatalf("Cannot print the workspace graph: %s.", err)
+		}
+	},
+}
+
+var orgCostReportCmd = &cobra.Command{
+	Use:   "cost-report [WORKSPACE...]",
+	Short: "Report the monthly costs of the workspaces, grouped by tag",
+	Long: `Report the monthly costs of the workspaces, grouped by tag.
+
+The latest cost estimate of each selected workspace, or of every workspace if none is
+selected, is aggregated into a CSV or Markdown table grouped by tag. A workspace with
+several tags is counted in each of their groups, so "--tag-prefix" can be used to only
+group by some of the tags, like "team-".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		selector, _ := cmd.Flags().GetString("selector")
+		format, _ := cmd.Flags().GetString("format")
+		prefix, _ := cmd.Flags().GetString("tag-prefix")
+		if format != "csv" && format != "markdown" {
+			log.Fatalf("Invalid format %q: must be csv or markdown.", format)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspaces.
+		var workspaces []*tfe.Workspace
+		if len(args) == 0 && selector == "" {
+			workspaces, err = listWorkspaces(client, organization)
+		} else {
+			workspaces, err = selectWorkspaces(client, organization, args, selector)
+		}
+		if err != nil {
+			log.Fatalf("Cannot select the workspaces: %s.", err)
+		}
+
+		// Retrieve their latest cost estimates.
+		costs := make([]*tfecli.WorkspaceCost, len(workspaces))
+		var eg errgroup.Group
+		for i, workspace := range workspaces {
+			i, workspace := i, workspace
+			eg.Go(func() error {
+				run, ce, err := readLatestCostEstimate(client, workspace.ID)
+				if err != nil {
+					return fmt.Errorf("cannot retrieve the cost estimate of %q: %s", workspace.Name, err)
+				}
+				if ce == nil {
+					log.Debugf("Workspace %q has no cost estimate.", workspace.Name)
+					return nil
+				}
+				c := &tfecli.WorkspaceCost{Workspace: workspace.Name, Tags: workspace.TagNames, Run: run.ID}
+				if c.Prior, err = tfecli.ParseCost(ce.PriorMonthlyCost); err != nil {
+					return err
+				}
+				if c.Proposed, err = tfecli.ParseCost(ce.ProposedMonthlyCost); err != nil {
+					return err
+				}
+				if c.Delta, err = tfecli.ParseCost(ce.DeltaMonthlyCost); err != nil {
+					return err
+				}
+				costs[i] = c
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			log.Fatalf("%s.", err)
+		}
+
+		// Print the report.
+		estimated := []tfecli.WorkspaceCost{}
+		for _, c := range costs {
+			if c != nil {
+				estimated = append(estimated, *c)
+			}
+		}
+		if len(estimated) < len(workspaces) {
+			log.Warningf("%d of the %d workspaces have no cost estimate.", len(workspaces)-len(estimated), len(workspaces))
+		}
+		if err := tfecli.WriteCostReport(os.Stdout, tfecli.GroupCostsByTag(estimated, prefix), format); err != nil {
+			log.Fatalf("Cannot print the cost report: %s.", err)
+		}
+	},
+}
+
+var orgAccessReportCmd = &cobra.Command{
+	Use:   "access-report",
+	Short: "Report the team access of every workspace in the organization",
+	Long:  `Report the team access of every workspace in the organization as a team × workspace matrix.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Index the teams by ID to resolve their names.
+		teams, err := listTeams(client, organization)
+		if err != nil {
+			log.Fatalf("Cannot list the teams for %q: %s.", organization, err)
+		}
+		teamNames := map[string]string{}
+		for _, t := range teams {
+			teamNames[t.ID] = t.Name
+		}
+
+		// List workspaces.
+		workspaces, err := listWorkspaces(client, organization)
+		if err != nil {
+			log.Fatalf("Cannot list the workspaces for  %q: %s.", organization, err)
+		}
+
+		// Retrieve the team accesses of every workspace.
+		accesses := make([][]*tfe.TeamAccess, len(workspaces))
+		var eg errgroup.Group
+		for i, workspace := range workspaces {
+			i, workspace := i, workspace
+			eg.Go(func() error {
+				a, err := listTeamAccesses(client, workspace.ID)
+				if err != nil {
+					return fmt.Errorf("cannot list the team accesses for %q: %s", workspace.Name, err)
+				}
+				accesses[i] = a
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			log.Fatalf("%s.", err)
+		}
+
+		// Build the matrix.
+		matrix := tfecli.NewAccessMatrix()
+		for i, workspace := range workspaces {
+			matrix.AddWorkspace(workspace.Name)
+			for _, a := range accesses[i] {
+				matrix.Set(workspace.Name, teamNames[a.Team.ID], string(a.Access))
+			}
+		}
+
+		// Print the matrix.
+		if err := matrix.Write(os.Stdout); err != nil {
+			log.Fatalf("Cannot print the access report: %s.", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(orgCmd)
+	orgCmd.AddCommand(orgGraphCmd)
+	orgCmd.AddCommand(orgCostReportCmd)
+	orgCmd.AddCommand(orgAccessReportCmd)
+
+	orgGraphCmd.Flags().String("format", "dot", "Specify the output format (dot, mermaid, json)")
+	orgGraphCmd.Flags().String("from", "", "Only show the workspaces impacted by a change in this workspace")
+	orgCostReportCmd.Flags().String("selector", "", "Report the costs of the workspaces matching the selector")
+	orgCostReportCmd.Flags().String("format", "csv", "Specify the output format (csv, markdown)")
+	orgCostReportCmd.Flags().String("tag-prefix", "", "Only group by the tags starting with this prefix")
+}
+
+// buildWorkspaceGraph connects the workspaces by their run triggers and remote state consumers.
+func buildWorkspaceGraph(client *tfe.Client, workspaces []*tfe.Workspace) (*tfecli.Graph, error) {
+	triggers := make([][]*tfe.RunTrigger, len(workspaces))
+	consumers := make([][]*tfe.Workspace, len(workspaces))
+
+	// Retrieve the relationships of every workspace.
+	var eg errgroup.Group
+	for i, workspace := range workspaces {
+		i, workspace := i, workspace
+		eg.Go(func() error {
+			t, err := listRunTriggers(client, workspace.ID, tfe.RunTriggerInbound)
+			if err != nil {
+				return fmt.Errorf("cannot list the run triggers for %q: %s", workspace.Name, err)
+			}
+			triggers[i] = t
+
+			if workspace.GlobalRemoteState {
+				return nil
+			}
+			c, err := listRemoteStateConsumers(client, workspace.ID)
+			if err != nil {
+				return fmt.Errorf("cannot list the remote state consumers for %q: %s", workspace.Name, err)
+			}
+			consumers[i] = c
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Connect the workspaces.
+	graph := tfecli.NewGraph()
+	for i, workspace := range workspaces {
+		graph.AddNode(tfecli.Node{Name: workspace.Name, GlobalRemoteState: workspace.GlobalRemoteState})
+		for _, t := range triggers[i] {
+			graph.AddEdge(tfecli.Edge{From: t.SourceableName, To: workspace.Name, Type: tfecli.EdgeRunTrigger})
+		}
+		for _, c := range consumers[i] {
+			graph.AddEdge(tfecli.Edge{From: workspace.Name, To: c.Name, Type: tfecli.EdgeRemoteState})
+		}
+	}
+
+	return graph, nil
+}
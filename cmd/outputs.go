@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var workspaceOutputsCmd = &cobra.Command{
+	Use:   "outputs [WORKSPACE]",
+	Short: "Print the outputs of a workspace",
+	Long: `Print the outputs of the current state version of a workspace.
+
+Sensitive outputs are skipped unless "--include-sensitive" is specified.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		format, _ := cmd.Flags().GetString("format")
+		raw, _ := cmd.Flags().GetString("raw")
+		includeSensitive, _ := cmd.Flags().GetBool("include-sensitive")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the outputs.
+		outputs, err := readOutputs(client, organization, name, includeSensitive)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the outputs of %q: %s.", name, err)
+		}
+
+		// Print a single value.
+		if raw != "" {
+			for _, o := range outputs {
+				if o.Name == raw {
+					fmt.Println(tfecli.OutputString(o.Value))
+					return
+				}
+			}
+			log.Fatalf("Cannot find output %q in workspace %q (sensitive outputs require --include-sensitive).", raw, name)
+		}
+
+		// Print the outputs.
+		if err := tfecli.WriteOutputs(os.Stdout, outputs, format); err != nil {
+			log.Fatalf("Cannot print the outputs of %q: %s.", name, err)
+		}
+	},
+}
+
+var workspaceExecCmd = &cobra.Command{
+	Use:   "exec [WORKSPACE] -- [COMMAND] [ARGS...]",
+	Short: "Run a command with the outputs of a workspace as environment variables",
+	Long: `Run a command with the outputs of a workspace as environment variables.
+
+Sensitive outputs are only injected if "--include-sensitive" is specified. The
+command exits with the exit code of the subprocess.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		includeSensitive, _ := cmd.Flags().GetBool("include-sensitive")
+		if cmd.ArgsLenAtDash() != 1 {
+			log.Fatalf("Invalid arguments: the command must follow \"--\".")
+		}
+		command := args[1:]
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the outputs.
+		outputs, err := readOutputs(client, organization, name, includeSensitive)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the outputs of %q: %s.", name, err)
+		}
+
+		// Inject them into the environment of the subprocess.
+		env := os.Environ()
+		for _, o := range outputs {
+			env = append(env, fmt.Sprintf("%s=%s", tfecli.EnvName(o.Name), tfecli.OutputString(o.Value)))
+		}
+
+		// Run the subprocess.
+		c := exec.Command(command[0], command[1:]...)
+		c.Env = env
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+			log.Fatalf("Cannot run %q: %s.", command[0], err)
+		}
+	},
+}
+
+func init() {
+	workspaceCmd.AddCommand(workspaceOutputsCmd)
+	workspaceCmd.AddCommand(workspaceExecCmd)
+
+	workspaceOutputsCmd.Flags().String("format", "json", "Specify the output format (json, dotenv, export, tfvars)")
+	workspaceOutputsCmd.Flags().String("raw", "", "Print the raw value of a single output")
+	workspaceOutputsCmd.Flags().Bool("include-sensitive", false, "Include the sensitive outputs")
+	workspaceExecCmd.Flags().Bool("include-sensitive", false, "Inject the sensitive outputs")
+}
+
+// readOutputs retrieves the outputs of the current state version of a workspace.
+func readOutputs(client *tfe.Client, organization, name string, includeSensitive bool) ([]tfecli.Output, error) {
+	// Retrieve the workspace.
+	workspace, err := readWorkspace(client, organization, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve workspace %q: %s", name, err)
+	}
+
+	// List the outputs.
+	list, err := client.StateVersionOutputs.ReadCurrent(context.Background(), workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []tfecli.Output{}
+	for _, o := range list.Items {
+		if o.Sensitive {
+			if !includeSensitive {
+				log.Debugf("Skipping sensitive output %q.", o.Name)
+				continue
+			}
+
+			// Sensitive values are only returned when reading the output itself.
+			sensitive, err := client.StateVersionOutputs.Read(context.Background(), o.ID)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read sensitive output %q: %s", o.Name, err)
+			}
+			o = sensitive
+		}
+		outputs = append(outputs, tfecli.Output{Name: o.Name, Value: o.Value, Sensitive: o.Sensitive})
+	}
+
+	return outputs, nil
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command.
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Manage TFE plans",
+	Long:  `Manage TFE plans.`,
+}
+
+var planSpeculativeCmd = &cobra.Command{
+	Use:   "speculative [WORKSPACE]",
+	Short: "Run a speculative plan of a local configuration",
+	Long: `Run a speculative plan of a local configuration.
+
+The directory is uploaded as a speculative configuration version, and once the plan
+is finished, a summary of the resource changes, of the policy checks and of the cost
+estimate is printed as Markdown, suitable for a pull request comment, or as JSON.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		dir, _ := cmd.Flags().GetString("dir")
+		format, _ := cmd.Flags().GetString("format")
+		message, _ := cmd.Flags().GetString("message")
+		if format != "markdown" && format != "json" {
+			log.Fatalf("Invalid format %q: must be markdown or json.", format)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Upload the configuration.
+		cv, err := uploadConfiguration(client, workspace, dir, true)
+		if err != nil {
+			log.Fatalf("Cannot upload the configuration of %q: %s.", name, err)
+		}
+
+		// Queue the plan.
+		options := tfe.RunCreateOptions{
+			ConfigurationVersion: cv,
+			Message:              tfe.String(message),
+		}
+		run, err := startRun(client, workspace, options)
+		if err != nil {
+			log.Fatalf("Cannot queue a plan in %q: %s.", name, err)
+		}
+		log.Infof("Run %q queued: %s.", run.ID, tfecli.RunURL(organization, name, run.ID))
+
+		// Wait for the plan.
+		runID := run.ID
+		run, err = pollRun(context.Background(), client, runID, func(r *tfe.Run) bool {
+			return tfecli.IsRunFinal(r.Status) || tfecli.IsRunActionable(r.Status)
+		})
+		if err != nil {
+			log.Fatalf("Cannot wait for run %q: %s.", runID, err)
+		}
+
+		// Summarize it.
+		report, err := buildPlanReport(client, organization, name, run)
+		if err != nil {
+			log.Fatalf("Cannot summarize run %q: %s.", run.ID, err)
+		}
+		if format == "json" {
+			err = report.WriteJSON(os.Stdout)
+		} else {
+			err = report.WriteMarkdown(os.Stdout)
+		}
+		if err != nil {
+			log.Fatalf("Cannot print the summary of run %q: %s.", run.ID, err)
+		}
+
+		// Exit with the status of the run.
+		os.Exit(tfecli.RunExitCode(run.Status, run.HasChanges))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planSpeculativeCmd)
+
+	planSpeculativeCmd.Flags().String("dir", ".", "Specify the directory containing the configuration")
+	planSpeculativeCmd.Flags().String("format", "markdown", "Specify the output format (markdown, json)")
+	planSpeculativeCmd.Flags().StringP("message", "m", "Speculative plan from tfe-cli", "Specify the message of the run")
+}
+
+// buildPlanReport summarizes the resource changes, the policy checks and the cost estimate of a run.
+func buildPlanReport(client *tfe.Client, organization, workspace string, run *tfe.Run) (*tfecli.PlanReport, error) {
+	report := &tfecli.PlanReport{
+		Workspace: workspace,
+		Run:       run.ID,
+		URL:       tfecli.RunURL(organization, workspace, run.ID),
+		Status:    string(run.Status),
+		Changes:   map[string][]string{},
+		Policies:  []tfecli.PolicyCheckSummary{},
+	}
+
+	// Retrieve the resource changes. The JSON plan is only available once the plan finished.
+	plan, err := readPlanJSON(client, run.Plan.ID)
+	if err != nil {
+		log.Warningf("Cannot retrieve the JSON plan of run %q: %s.", run.ID, err)
+	} else {
+		report.Changes = plan.ChangesByAction()
+	}
+
+	// Retrieve the policy checks.
+	checks, err := listPolicyChecks(client, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the policy checks: %s", err)
+	}
+	for _, c := range checks {
+		summary := tfecli.PolicyCheckSummary{
+			Scope:  string(c.Scope),
+			Status: string(c.Status),
+		}
+		if c.Result != nil {
+			summary.Passed = c.Result.Passed
+			summary.AdvisoryFailed = c.Result.AdvisoryFailed
+			summary.SoftFailed = c.Result.SoftFailed
+			summary.HardFailed = c.Result.HardFailed
+		}
+		report.Policies = append(report.Policies, summary)
+	}
+
+	// Retrieve the cost estimate.
+	ce, err := readCostEstimate(client, run)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve the cost estimate: %s", err)
+	}
+	if ce != nil && ce.Status == tfe.CostEstimateFinished {
+		report.Cost = &tfecli.CostDelta{
+			Prior:    ce.PriorMonthlyCost,
+			Proposed: ce.ProposedMonthlyCost,
+			Delta:    ce.DeltaMonthlyCost,
+		}
+	}
+
+	return report, nil
+}
+
+// readPlanJSON downloads and parses the JSON execution plan of a plan.
+func readPlanJSON(client *tfe.Client, planID string) (*tfecli.PlanJSON, error) {
+	data, err := client.Plans.ReadJSONOutput(context.Background(), planID)
+	if err != nil {
+		return nil, err
+	}
+	return tfecli.ParsePlanJSON(data)
+}
+
+// listPolicyChecks lists the policy checks of a run.
+func listPolicyChecks(client *tfe.Client, runID string) ([]*tfe.PolicyCheck, error) {
+	results := []*tfe.PolicyCheck{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.PolicyCheckListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			},
+		}
+		p, err := client.PolicyChecks.List(context.Background(), runID, &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, p.Items...)
+
+		// Check if there is another poage to retrieve.
+		if p.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+// readCostEstimate retrieves the cost estimate of a run, if any.
+func readCostEstimate(client *tfe.Client, run *tfe.Run) (*tfe.CostEstimate, error) {
+	if run.CostEstimate == nil || run.CostEstimate.ID == "" {
+		return nil, nil
+	}
+	return client.CostEstimates.Read(context.Background(), run.CostEstimate.ID)
+}
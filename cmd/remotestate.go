@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// workspaceRemoteStateCmd represents the workspace remote-state command.
+var workspaceRemoteStateCmd = &cobra.Command{
+	Use:   "remote-state",
+	Short: "Manage the remote state sharing of TFE workspaces",
+	Long:  `Manage the remote state sharing of TFE workspaces.`,
+}
+
+var workspaceRemoteStateGlobalCmd = &cobra.Command{
+	Use:   "global [WORKSPACE]",
+	Short: "Share the state of a workspace with the whole organization",
+	Long:  `Share the state of a workspace with the whole organization.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		disable, _ := cmd.Flags().GetBool("disable")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Update the workspace.
+		options := tfe.WorkspaceUpdateOptions{
+			GlobalRemoteState: tfe.Bool(!disable),
+		}
+		if _, err := updateWorkspaceByID(client, workspace.ID, options); err != nil {
+			log.Fatalf("Cannot update workspace %q: %s.", name, err)
+		}
+
+		if disable {
+			log.Infof("Global remote state disabled for workspace %q.", name)
+			return
+		}
+		log.Infof("Global remote state enabled for workspace %q.", name)
+	},
+}
+
+// workspaceRemoteStateConsumersCmd represents the workspace remote-state consumers command.
+var workspaceRemoteStateConsumersCmd = &cobra.Command{
+	Use:   "consumers",
+	Short: "Manage the workspaces allowed to read the state of a workspace",
+	Long:  `Manage the workspaces allowed to read the state of a workspace.`,
+}
+
+var workspaceRemoteStateConsumersListCmd = &cobra.Command{
+	Use:   "list [WORKSPACE]",
+	Short: "List the remote state consumers of a workspace",
+	Long:  `List the remote state consumers of a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+		if workspace.GlobalRemoteState {
+			log.Warningf("Workspace %q shares its state with the whole organization.", name)
+		}
+
+		// List the consumers.
+		consumers, err := listRemoteStateConsumers(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot list the remote state consumers for %q: %s.", name, err)
+		}
+
+		// Print the consumer names.
+		for _, c := range consumers {
+			fmt.Println(c.Name)
+		}
+	},
+}
+
+var workspaceRemoteStateConsumersAddCmd = &cobra.Command{
+	Use:   "add [WORKSPACE] [CONSUMER...]",
+	Short: "Allow workspaces to read the state of a workspace",
+	Long:  `Allow workspaces to read the state of a workspace.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+		if workspace.GlobalRemoteState {
+			log.Warningf("Workspace %q shares its state with the whole organization: the consumers have no effect until global remote state is disabled.", name)
+		}
+
+		// Retrieve the consumers.
+		consumers, err := readWorkspaceRefs(client, organization, args[1:])
+		if err != nil {
+			log.Fatalf("Cannot add remote state consumers to %q: %s.", name, err)
+		}
+
+		// Add them.
+		options := tfe.WorkspaceAddRemoteStateConsumersOptions{
+			Workspaces: consumers,
+		}
+		if err := client.Workspaces.AddRemoteStateConsumers(context.Background(), workspace.ID, options); err != nil {
+			log.Fatalf("Cannot add remote state consumers to %q: %s.", name, err)
+		}
+		log.Infof("Remote state consumers added to %q successfully.", name)
+	},
+}
+
+var workspaceRemoteStateConsumersRemoveCmd = &cobra.Command{
+	Use:   "remove [WORKSPACE] [CONSUMER...]",
+	Short: "Prevent workspaces from reading the state of a workspace",
+	Long:  `Prevent workspaces from reading the state of a workspace.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Retrieve the consumers.
+		consumers, err := readWorkspaceRefs(client, organization, args[1:])
+		if err != nil {
+			log.Fatalf("Cannot remove remote state consumers from %q: %s.", name, err)
+		}
+
+		// Remove them.
+		options := tfe.WorkspaceRemoveRemoteStateConsumersOptions{
+			Workspaces: consumers,
+		}
+		if err := client.Workspaces.RemoveRemoteStateConsumers(context.Background(), workspace.ID, options); err != nil {
+			log.Fatalf("Cannot remove remote state consumers from %q: %s.", name, err)
+		}
+		log.Infof("Remote state consumers removed from %q successfully.", name)
+	},
+}
+
+func init() {
+	workspaceCmd.AddCommand(workspaceRemoteStateCmd)
+	workspaceRemoteStateCmd.AddCommand(workspaceRemoteStateGlobalCmd)
+	workspaceRemoteStateCmd.AddCommand(workspaceRemoteStateConsumersCmd)
+	workspaceRemoteStateConsumersCmd.AddCommand(workspaceRemoteStateConsumersListCmd)
+	workspaceRemoteStateConsumersCmd.AddCommand(workspaceRemoteStateConsumersAddCmd)
+	workspaceRemoteStateConsumersCmd.AddCommand(workspaceRemoteStateConsumersRemoveCmd)
+
+	workspaceRemoteStateGlobalCmd.Flags().Bool("disable", false, "Stop sharing the state with the whole organization")
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// runCmd represents the run command.
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Manage TFE runs",
+	Long:  `Manage TFE runs.`,
+}
+
+var runStartCmd = &cobra.Command{
+	Use:   "start [WORKSPACE...]",
+	Short: "Queue runs",
+	Long: `Queue runs in one or several workspaces.
+
+The workspaces are specified by name, or with a selector made of comma separated
+"name=GLOB", "tag=TAG" and "!tag=TAG" terms. The run variables must be expressed
+as HCL literals, for instance --var 'region="us-east-1"'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		selector, _ := cmd.Flags().GetString("selector")
+		if len(args) == 0 && selector == "" {
+			log.Fatalf("Cannot queue runs: specify at least one workspace or a selector.")
+		}
+		options, err := readRunCreateOptions(cmd)
+		if err != nil {
+			log.Fatalf("Cannot queue runs: %s.", err)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspaces.
+		workspaces, err := selectWorkspaces(client, organization, args, selector)
+		if err != nil {
+			log.Fatalf("Cannot select the workspaces: %s.", err)
+		}
+		if len(workspaces) == 0 {
+			log.Warningf("No workspace matches the selector %q.", selector)
+			return
+		}
+
+		// Queue the runs.
+		runs := make([]*tfe.Run, len(workspaces))
+		var eg errgroup.Group
+		for i, workspace := range workspaces {
+			i, workspace := i, workspace
+			eg.Go(func() error {
+				r, err := startRun(client, workspace, options)
+				if err != nil {
+					return fmt.Errorf("cannot queue a run in %q: %s", workspace.Name, err)
+				}
+				runs[i] = r
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			log.Fatalf("%s.", err)
+		}
+
+		// Print the runs.
+		for i, r := range runs {
+			if len(workspaces) == 1 {
+				fmt.Printf("%s %s\n", r.ID, tfecli.RunURL(organization, workspaces[i].Name, r.ID))
+				continue
+			}
+			fmt.Printf("%s: %s %s\n", workspaces[i].Name, r.ID, tfecli.RunURL(organization, workspaces[i].Name, r.ID))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.AddCommand(runStartCmd)
+
+	addRunCreateFlags(runStartCmd)
+	runStartCmd.Flags().String("selector", "", "Queue runs in the workspaces matching the selector")
+}
+
+// addRunCreateFlags adds the flags describing a new run.
+func addRunCreateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("message", "m", "Queued from tfe-cli", "Specify the run message")
+	cmd.Flags().StringArray("target", []string{}, "Limit the planning to the given resource address")
+	cmd.Flags().StringArray("replace", []string{}, "Force the replacement of the given resource address")
+	cmd.Flags().Bool("destroy", false, "Destroy all the resources")
+	cmd.Flags().Bool("refresh-only", false, "Only refresh the state")
+	cmd.Flags().Bool("plan-only", false, "Queue a speculative plan which cannot be applied")
+	cmd.Flags().Bool("auto-apply", false, "Override the auto-apply setting of the workspace")
+	cmd.Flags().StringArray("var", []string{}, "Set a run variable (key=HCL value)")
+}
+
+// readRunCreateOptions reads the flags added by addRunCreateFlags.
+func readRunCreateOptions(cmd *cobra.Command) (tfe.RunCreateOptions, error) {
+	message, _ := cmd.Flags().GetString("message")
+	targets, _ := cmd.Flags().GetStringArray("target")
+	replaces, _ := cmd.Flags().GetStringArray("replace")
+	destroy, _ := cmd.Flags().GetBool("destroy")
+	refreshOnly, _ := cmd.Flags().GetBool("refresh-only")
+	planOnly, _ := cmd.Flags().GetBool("plan-only")
+	vars, _ := cmd.Flags().GetStringArray("var")
+
+	options := tfe.RunCreateOptions{
+		Message:      tfe.String(message),
+		TargetAddrs:  targets,
+		ReplaceAddrs: replaces,
+		IsDestroy:    tfe.Bool(destroy),
+		RefreshOnly:  tfe.Bool(refreshOnly),
+		PlanOnly:     tfe.Bool(planOnly),
+	}
+	if destroy && refreshOnly {
+		return options, fmt.Errorf("--destroy and --refresh-only are mutually exclusive")
+	}
+
+	// Only override the workspace setting when requested.
+	if cmd.Flags().Changed("auto-apply") {
+		autoApply, _ := cmd.Flags().GetBool("auto-apply")
+		options.AutoApply = tfe.Bool(autoApply)
+	}
+
+	// Parse the run variables.
+	for _, v := range vars {
+		splitV := strings.SplitN(v, "=", 2)
+		if len(splitV) != 2 {
+			return options, fmt.Errorf("invalid variable %q: the format must be key=value", v)
+		}
+		options.Variables = append(options.Variables, &tfe.RunVariable{Key: splitV[0], Value: splitV[1]})
+	}
+
+	return options, nil
+}
+
+func startRun(client *tfe.Client, workspace *tfe.Workspace, options tfe.RunCreateOptions) (*tfe.Run, error) {
+	options.Workspace = workspace
+	r, err := client.Runs.Create(context.Background(), options)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runApplyCmd = newRunActionCmd(tfecli.RunActionApply, "Confirm a run and apply its plan")
+var runDiscardCmd = newRunActionCmd(tfecli.RunActionDiscard, "Discard a run waiting for a confirmation")
+var runCancelCmd = newRunActionCmd(tfecli.RunActionCancel, "Cancel a run being planned or applied")
+var runForceCancelCmd = newRunActionCmd(tfecli.RunActionForceCancel, "Force-cancel a run which could not be canceled")
+var runForceExecuteCmd = newRunActionCmd(tfecli.RunActionForceExecute, "Discard the runs ahead of a pending run and start it")
+
+func init() {
+	runCmd.AddCommand(runApplyCmd)
+	runCmd.AddCommand(runDiscardCmd)
+	runCmd.AddCommand(runCancelCmd)
+	runCmd.AddCommand(runForceCancelCmd)
+	runCmd.AddCommand(runForceExecuteCmd)
+}
+
+// newRunActionCmd creates a command performing an action on a run, once the run status
+// and the token permissions have been validated.
+func newRunActionCmd(action, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s [RUN_ID]", action),
+		Short: short,
+		Long:  fmt.Sprintf("%s.", short),
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Read the flags.
+			runID := args[0]
+			comment, _ := cmd.Flags().GetString("comment")
+
+			// Setup the command.
+			_, client, err := tfecli.Setup(cmd)
+			if err != nil {
+				log.Fatalf("Cannot execute the command: %s.", err)
+			}
+
+			// Retrieve the run.
+			run, err := client.Runs.Read(context.Background(), runID)
+			if err != nil {
+				log.Fatalf("Cannot retrieve run %q: %s.", runID, err)
+			}
+
+			// Ensure the action is allowed.
+			if err := tfecli.CheckRunAction(run, action); err != nil {
+				log.Fatalf("Cannot %s run %q: %s.", action, runID, err)
+			}
+
+			// Perform it.
+			if err := performRunAction(client, runID, action, comment); err != nil {
+				log.Fatalf("Cannot %s run %q: %s.", action, runID, err)
+			}
+			log.Infof("Run %q: %s requested successfully.", runID, action)
+		},
+	}
+
+	// The force-execute action has no comment: the flag is rejected as unknown.
+	if action != tfecli.RunActionForceExecute {
+		cmd.Flags().String("comment", "", "Specify a comment about the action")
+	}
+	return cmd
+}
+
+// performRunAction performs an action on a run, with an optional comment.
+func performRunAction(client *tfe.Client, runID, action, comment string) error {
+	var c *string
+	if comment != "" {
+		c = tfe.String(comment)
+	}
+
+	ctx := context.Background()
+	switch action {
+	case tfecli.RunActionApply:
+		return client.Runs.Apply(ctx, runID, tfe.RunApplyOptions{Comment: c})
+	case tfecli.RunActionDiscard:
+		return client.Runs.Discard(ctx, runID, tfe.RunDiscardOptions{Comment: c})
+	case tfecli.RunActionCancel:
+		return client.Runs.Cancel(ctx, runID, tfe.RunCancelOptions{Comment: c})
+	case tfecli.RunActionForceCancel:
+		return client.Runs.ForceCancel(ctx, runID, tfe.RunForceCancelOptions{Comment: c})
+	case tfecli.RunActionForceExecute:
+		// The force-execute action is not exposed by go-tfe, and has no comment flag.
+		req, err := client.NewRequest("POST", fmt.Sprintf("runs/%s/actions/force-execute", url.QueryEscape(runID)), nil)
+		if err != nil {
+			return err
+		}
+		return req.Do(ctx, nil)
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runCostCmd = &cobra.Command{
+	Use:   "cost [RUN_ID]",
+	Short: "Show the cost estimate of a run",
+	Long:  `Show the prior and proposed monthly costs of the resources of a run, and their delta.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+
+		// Setup the command.
+		_, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the run.
+		run, err := client.Runs.Read(context.Background(), runID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve run %q: %s.", runID, err)
+		}
+
+		// Retrieve the cost estimate.
+		ce, err := readCostEstimate(client, run)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the cost estimate of %q: %s.", runID, err)
+		}
+		if ce == nil {
+			log.Fatalf("Cannot retrieve the cost estimate of %q: cost estimation is not enabled.", runID)
+		}
+		if ce.Status != tfe.CostEstimateFinished {
+			log.Fatalf("Cannot retrieve the cost estimate of %q: the cost estimate is %s.", runID, ce.Status)
+		}
+		details, err := readCostEstimateLog(client, ce.ID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the cost estimate of %q: %s.", runID, err)
+		}
+
+		// Print the costs.
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "RESOURCE\tPRIOR\tPROPOSED\tDELTA\t")
+		for _, r := range details.Resources.Matched {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", r.Address, tfecli.FormatCost(r.Prior, false), tfecli.FormatCost(r.Proposed, false), tfecli.FormatCost(r.Delta, true))
+		}
+		fmt.Fprintf(tw, "TOTAL\t%s\t%s\t%s\t\n", tfecli.FormatCost(ce.PriorMonthlyCost, false), tfecli.FormatCost(ce.ProposedMonthlyCost, false), tfecli.FormatCost(ce.DeltaMonthlyCost, true))
+		tw.Flush()
+		if ce.UnmatchedResourcesCount > 0 {
+			log.Warningf("%d resources could not be estimated.", ce.UnmatchedResourcesCount)
+		}
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runCostCmd)
+}
+
+// readCostEstimateLog retrieves the details of a cost estimate, which are returned as its logs.
+func readCostEstimateLog(client *tfe.Client, costEstimateID string) (*tfecli.CostEstimateLog, error) {
+	logs, err := client.CostEstimates.Logs(context.Background(), costEstimateID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(logs)
+	if err != nil {
+		return nil, err
+	}
+	return tfecli.ParseCostEstimateLog(data)
+}
+
+// readLatestCostEstimate retrieves the latest finished cost estimate of a workspace, if any,
+// looking at its 20 most recent runs.
+func readLatestCostEstimate(client *tfe.Client, workspaceID string) (*tfe.Run, *tfe.CostEstimate, error) {
+	options := tfe.RunListOptions{
+		ListOptions: tfe.ListOptions{
+			PageSize: 20,
+		},
+	}
+	runs, err := client.Runs.List(context.Background(), workspaceID, &options)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range runs.Items {
+		ce, err := readCostEstimate(client, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ce != nil && ce.Status == tfe.CostEstimateFinished {
+			return r, ce, nil
+		}
+	}
+	return nil, nil, nil
+}
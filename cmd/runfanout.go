@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runFanoutCmd = &cobra.Command{
+	Use:   "fanout [WORKSPACE...]",
+	Short: "Queue and track runs across many workspaces",
+	Long: `Queue the same run in many workspaces, track each of them to completion, and
+apply the plans whose changes are allowed.
+
+The workspaces are specified by name, or with a selector. At most "--parallelism"
+runs are in progress at the same time. A plan is applied only if all its resource
+changes use the actions allowed by "--allow", which defaults to create, update and
+read: the plans destroying or replacing resources are left for review. With
+"--plan-only", nothing is applied.
+
+A status table is refreshed on a terminal while the runs progress, or the status
+transitions are printed as they happen, and a summary with the exit code of each
+workspace is printed at the end. The command exits with 1 if tfe-cli
+failed for any workspace, otherwise with 4 if any run failed, otherwise with 2 if
+any plan was not applied, and with 0 when all the runs were applied or had no
+changes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		selector, _ := cmd.Flags().GetString("selector")
+		parallelism, _ := cmd.Flags().GetInt("parallelism")
+		allowed, _ := cmd.Flags().GetStringSlice("allow")
+		if len(args) == 0 && selector == "" {
+			log.Fatalf("Cannot queue runs: specify at least one workspace or a selector.")
+		}
+		if parallelism < 1 {
+			log.Fatalf("Invalid parallelism %d: must be at least 1.", parallelism)
+		}
+		if cmd.Flags().Changed("auto-apply") {
+			log.Fatalf("Cannot queue runs: the plans are applied according to --allow.")
+		}
+		if err := tfecli.ValidatePlanActions(allowed); err != nil {
+			log.Fatalf("Invalid allowed changes: %s.", err)
+		}
+		options, err := readRunCreateOptions(cmd)
+		if err != nil {
+			log.Fatalf("Cannot queue runs: %s.", err)
+		}
+		options.AutoApply = tfe.Bool(false)
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspaces.
+		workspaces, err := selectWorkspaces(client, organization, args, selector)
+		if err != nil {
+			log.Fatalf("Cannot select the workspaces: %s.", err)
+		}
+		if len(workspaces) == 0 {
+			log.Warningf("No workspace matches the selector %q.", selector)
+			return
+		}
+
+		// Track the runs, refreshing the status table on a terminal.
+		tracker := newFanoutTracker(workspaces, os.Stdout, isTerminal(os.Stdout))
+		stop := make(chan struct{})
+		refreshed := make(chan struct{})
+		go func() {
+			defer close(refreshed)
+			tracker.refresh(os.Stdout, stop)
+		}()
+
+		// Process the workspaces.
+		sem := make(chan struct{}, parallelism)
+		var wg sync.WaitGroup
+		for i, workspace := range workspaces {
+			i, workspace := i, workspace
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				fanoutRun(client, workspace, options, allowed, tracker, i)
+			}()
+		}
+		wg.Wait()
+		close(stop)
+		<-refreshed
+
+		// Print the summary.
+		fmt.Println()
+		os.Exit(tracker.writeSummary(os.Stdout))
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runFanoutCmd)
+
+	addRunCreateFlags(runFanoutCmd)
+	runFanoutCmd.Flags().String("selector", "", "Queue runs in the workspaces matching the selector")
+	runFanoutCmd.Flags().Int("parallelism", 10, "Specify the maximum number of runs in progress at the same time")
+	runFanoutCmd.Flags().StringSlice("allow", []string{tfecli.ActionCreate, tfecli.ActionUpdate, tfecli.ActionRead}, "Specify the resource change actions allowing a plan to be applied")
+}
+
+// fanoutRun queues a run in a workspace, applies it if its changes are allowed, and
+// tracks it to completion.
+func fanoutRun(client *tfe.Client, workspace *tfe.Workspace, options tfe.RunCreateOptions, allowed []string, tracker *fanoutTracker, i int) {
+	fail := func(format string, args ...interface{}) {
+		tracker.update(i, func(s *fanoutStatus) {
+			s.note = fmt.Sprintf(format, args...)
+			s.exitCode = tfecli.ExitError
+		})
+	}
+
+	// Queue the run.
+	run, err := startRun(client, workspace, options)
+	if err != nil {
+		fail("cannot queue the run: %s", err)
+		return
+	}
+	tracker.update(i, func(s *fanoutStatus) { s.run, s.status = run.ID, string(run.Status) })
+	onStatus := func(r *tfe.Run) {
+		tracker.update(i, func(s *fanoutStatus) { s.status = string(r.Status) })
+	}
+
+	// Wait for the plan.
+	run, err = pollRun(context.Background(), client, run.ID, func(r *tfe.Run) bool {
+		onStatus(r)
+		return tfecli.IsRunFinal(r.Status) || tfecli.IsRunActionable(r.Status)
+	})
+	if err != nil {
+		fail("cannot track the run: %s", err)
+		return
+	}
+	if plan, err := client.Plans.Read(context.Background(), run.Plan.ID); err == nil {
+		tracker.update(i, func(s *fanoutStatus) { s.changes = tfecli.PlanSummary(plan) })
+	}
+
+	// Apply the plan if its changes are allowed.
+	if run.Actions != nil && run.Actions.IsConfirmable {
+		plan, err := readPlanJSON(client, run.Plan.ID)
+		if err != nil {
+			fail("cannot retrieve the JSON plan: %s", err)
+			return
+		}
+		disallowed, err := plan.DisallowedChanges(allowed)
+		if err != nil {
+			fail("%s", err)
+			return
+		}
+		if len(disallowed) > 0 {
+			tracker.update(i, func(s *fanoutStatus) {
+				s.note = fmt.Sprintf("not applied: %s would be %s", disallowed[0].Address, actionPastTenses[disallowed[0].Action()])
+				if len(disallowed) > 1 {
+					s.note += fmt.Sprintf(" (and %d more)", len(disallowed)-1)
+				}
+			})
+		} else {
+			comment := tfe.String("Applied by tfe-cli fanout: all the changes are allowed.")
+			if err := client.Runs.Apply(context.Background(), run.ID, tfe.RunApplyOptions{Comment: comment}); err != nil {
+				fail("cannot apply the run: %s", err)
+				return
+			}
+			run, err = pollRun(context.Background(), client, run.ID, func(r *tfe.Run) bool {
+				onStatus(r)
+				return tfecli.IsRunFinal(r.Status)
+			})
+			if err != nil {
+				fail("cannot track the run: %s", err)
+				return
+			}
+		}
+	}
+
+	tracker.update(i, func(s *fanoutStatus) {
+		s.status = string(run.Status)
+		s.exitCode = tfecli.RunExitCode(run.Status, run.HasChanges)
+	})
+}
+
+// actionPastTenses describes the resource change actions in the past tense.
+var actionPastTenses = map[string]string{
+	tfecli.ActionCreate:  "created",
+	tfecli.ActionUpdate:  "updated",
+	tfecli.ActionReplace: "replaced",
+	tfecli.ActionDelete:  "deleted",
+	tfecli.ActionRead:    "read",
+}
+
+// fanoutStatus represents the progress of a run in a workspace.
+type fanoutStatus struct {
+	workspace string
+	run       string
+	status    string
+	changes   string
+	note      string
+	exitCode  int
+}
+
+// fanoutTracker tracks the progress of the runs of a fanout.
+type fanoutTracker struct {
+	mu       sync.Mutex
+	statuses []*fanoutStatus
+	out      io.Writer
+	live     bool
+}
+
+func newFanoutTracker(workspaces []*tfe.Workspace, out io.Writer, live bool) *fanoutTracker {
+	t := &fanoutTracker{out: out, live: live}
+	for _, w := range workspaces {
+		t.statuses = append(t.statuses, &fanoutStatus{workspace: w.Name, status: "waiting"})
+	}
+	return t
+}
+
+// update applies a change to the status of a workspace. Without a live table, the
+// status transitions and the notes are printed as they happen.
+func (t *fanoutTracker) update(i int, f func(*fanoutStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.statuses[i]
+	previous, previousNote := s.status, s.note
+	f(s)
+	if t.live {
+		return
+	}
+	if s.status != previous {
+		fmt.Fprintf(t.out, "%s: %s -> %s\n", s.workspace, previous, s.status)
+	}
+	if s.note != previousNote {
+		fmt.Fprintf(t.out, "%s: %s\n", s.workspace, s.note)
+	}
+}
+
+// refresh redraws the status table every 2 seconds until stopped, if the table is live.
+func (t *fanoutTracker) refresh(w io.Writer, stop <-chan struct{}) {
+	if !t.live {
+		<-stop
+		return
+	}
+	lines := 0
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		// Move the cursor up and clear the previous table.
+		b := &bytes.Buffer{}
+		if lines > 0 {
+			fmt.Fprintf(b, "\033[%dA\033[J", lines)
+		}
+		table := t.table(false)
+		lines = strings.Count(table, "\n")
+		b.WriteString(table)
+		w.Write(b.Bytes())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// table renders the statuses of the runs, with their exit code once completed.
+func (t *fanoutTracker) table(summary bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := &strings.Builder{}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	if summary {
+		fmt.Fprintln(tw, "WORKSPACE\tRUN\tSTATUS\tCHANGES\tEXIT\tNOTE")
+	} else {
+		fmt.Fprintln(tw, "WORKSPACE\tRUN\tSTATUS\tCHANGES\tNOTE")
+	}
+	for _, s := range t.statuses {
+		run, changes := s.run, s.changes
+		if run == "" {
+			run = "-"
+		}
+		if changes == "" {
+			changes = "-"
+		}
+		if summary {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", s.workspace, run, s.status, changes, s.exitCode, s.note)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.workspace, run, s.status, changes, s.note)
+	}
+	tw.Flush()
+	return b.String()
+}
+
+// writeSummary writes the final status of the runs, and returns the overall exit code.
+func (t *fanoutTracker) writeSummary(w io.Writer) int {
+	io.WriteString(w, t.table(true))
+	codes := []int{}
+	for _, s := range t.statuses {
+		codes = append(codes, s.exitCode)
+	}
+	return tfecli.AggregateExitCodes(codes)
+}
+
+// isTerminal reports whether a file is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
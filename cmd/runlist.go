@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var runListCmd = &cobra.Command{
+	Use:   "list [WORKSPACE...]",
+	Short: "List the runs of workspaces",
+	Long: `List the runs of one or several workspaces, most recent first.
+
+The "--status" and "--source" filters accept comma separated values. The "--since"
+filter accepts a date, or a duration like 36h or 7d.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		selector, _ := cmd.Flags().GetString("selector")
+		status, _ := cmd.Flags().GetString("status")
+		source, _ := cmd.Flags().GetString("source")
+		rawSince, _ := cmd.Flags().GetString("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if len(args) == 0 && selector == "" {
+			log.Fatalf("Cannot list runs: specify at least one workspace or a selector.")
+		}
+		var since time.Time
+		if rawSince != "" {
+			var err error
+			if since, err = tfecli.ParseSince(rawSince, time.Now()); err != nil {
+				log.Fatalf("Cannot list runs: %s.", err)
+			}
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspaces.
+		workspaces, err := selectWorkspaces(client, organization, args, selector)
+		if err != nil {
+			log.Fatalf("Cannot select the workspaces: %s.", err)
+		}
+
+		// List the runs of every workspace.
+		runs := make([][]*tfe.Run, len(workspaces))
+		var eg errgroup.Group
+		for i, workspace := range workspaces {
+			i, workspace := i, workspace
+			eg.Go(func() error {
+				options := tfe.RunListOptions{
+					Status:  status,
+					Source:  source,
+					Include: []tfe.RunIncludeOpt{tfe.RunPlan, tfe.RunCreatedBy},
+				}
+				r, err := listRuns(client, workspace.ID, options, since, limit)
+				if err != nil {
+					return fmt.Errorf("cannot list the runs for %q: %s", workspace.Name, err)
+				}
+				runs[i] = r
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			log.Fatalf("%s.", err)
+		}
+
+		// Print the runs.
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "WORKSPACE\tRUN\tSTATUS\tSOURCE\tCREATED BY\tCREATED AT\tCHANGES\tPLAN\tAPPLY\tMESSAGE")
+		for i, workspace := range workspaces {
+			for _, r := range runs[i] {
+				createdBy := "-"
+				if r.CreatedBy != nil && r.CreatedBy.Username != "" {
+					createdBy = r.CreatedBy.Username
+				}
+				fmt.Fprintf(
+					tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					workspace.Name, r.ID, r.Status, r.Source, createdBy, r.CreatedAt.Format(time.RFC3339),
+					tfecli.PlanSummary(r.Plan), tfecli.FormatDuration(tfecli.PlanDuration(r)),
+					tfecli.FormatDuration(tfecli.ApplyDuration(r)), shortMessage(r.Message),
+				)
+			}
+		}
+		tw.Flush()
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runListCmd)
+
+	runListCmd.Flags().String("selector", "", "List the runs of the workspaces matching the selector")
+	runListCmd.Flags().String("status", "", "Only list the runs with these statuses, e.g. errored,applied")
+	runListCmd.Flags().String("source", "", "Only list the runs from these sources, e.g. tfe-api,tfe-ui")
+	runListCmd.Flags().String("since", "", "Only list the runs created after this date or duration")
+	runListCmd.Flags().Int("limit", 20, "Specify the maximum number of runs per workspace (0 for all)")
+}
+
+// listRuns lists the runs of a workspace created after since, most recent first.
+// A limit of 0 lists all of them.
+func listRuns(client *tfe.Client, workspaceID string, options tfe.RunListOptions, since time.Time, limit int) ([]*tfe.Run, error) {
+	results := []*tfe.Run{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options.ListOptions = tfe.ListOptions{
+			PageNumber: currentPage,
+		}
+		r, err := client.Runs.List(context.Background(), workspaceID, &options)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range r.Items {
+			// The runs are sorted by creation date, so the next ones are older.
+			if run.CreatedAt.Before(since) {
+				return results, nil
+			}
+			results = append(results, run)
+			if limit > 0 && len(results) >= limit {
+				return results, nil
+			}
+		}
+
+		// Check if there is another poage to retrieve.
+		if r.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+// shortMessage returns the first line of a run message, truncated to 50 characters.
+func shortMessage(message string) string {
+	message = strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if runes := []rune(message); len(runes) > 50 {
+		return string(runes[:49]) + "…"
+	}
+	return message
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runLogsCmd = &cobra.Command{
+	Use:   "logs [RUN_ID|WORKSPACE]",
+	Short: "Print the logs of a run",
+	Long: `Print the plan and apply logs of a run, or of the latest run of a workspace.
+
+The command exits with a code reflecting the status of the run:
+  0: the run was applied
+  2: the plan has changes which are not applied
+  3: the plan has no changes
+  4: the run errored, or was canceled or discarded
+  7: the run is still in progress, without "--follow"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		ref := args[0]
+		follow, _ := cmd.Flags().GetBool("follow")
+		phase, _ := cmd.Flags().GetString("phase")
+		if phase != "" && phase != "plan" && phase != "apply" {
+			log.Fatalf("Invalid phase %q: must be plan or apply.", phase)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the run.
+		run, err := resolveRun(client, organization, ref)
+		if err != nil {
+			log.Fatalf("Cannot retrieve run %q: %s.", ref, err)
+		}
+
+		// Print the logs.
+		run, err = printRunLogs(client, run, phase, follow, os.Stdout)
+		if err != nil {
+			log.Fatalf("Cannot print the logs of %q: %s.", ref, err)
+		}
+
+		// Exit with the status of the run.
+		log.Infof("Run %q is %s.", run.ID, run.Status)
+		os.Exit(tfecli.RunExitCode(run.Status, run.HasChanges))
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runLogsCmd)
+
+	runLogsCmd.Flags().BoolP("follow", "f", false, "Stream the logs until the run completes")
+	runLogsCmd.Flags().String("phase", "", "Only print the logs of a phase (plan, apply)")
+}
+
+// printRunLogs prints the logs of the phases of a run, all of them if phase is empty,
+// and returns the up to date run. When following the logs, it returns once the run is
+// completed or requires an action.
+func printRunLogs(client *tfe.Client, run *tfe.Run, phase string, follow bool, w io.Writer) (*tfe.Run, error) {
+	// Print the plan logs.
+	if phase != "apply" {
+		if err := printPlanLogs(client, run.Plan.ID, follow, w); err != nil {
+			return run, fmt.Errorf("cannot print the plan logs: %s", err)
+		}
+	}
+
+	// Print the apply logs.
+	if phase != "plan" {
+		if err := printApplyLogs(client, run, follow, w); err != nil {
+			return run, fmt.Errorf("cannot print the apply logs: %s", err)
+		}
+	}
+
+	// Retrieve the status of the run.
+	if !follow {
+		return client.Runs.Read(context.Background(), run.ID)
+	}
+	return pollRun(context.Background(), client, run.ID, func(r *tfe.Run) bool {
+		return tfecli.IsRunFinal(r.Status) || tfecli.IsRunActionable(r.Status)
+	})
+}
+
+// resolveRun retrieves a run by its ID, or the latest run of a workspace.
+func resolveRun(client *tfe.Client, organization, ref string) (*tfe.Run, error) {
+	if strings.HasPrefix(ref, "run-") {
+		return client.Runs.Read(context.Background(), ref)
+	}
+
+	// Retrieve the latest run of the workspace.
+	workspace, err := readWorkspace(client, organization, ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve workspace %q: %s", ref, err)
+	}
+	options := tfe.RunListOptions{
+		ListOptions: tfe.ListOptions{
+			PageSize: 1,
+		}}
+	runs, err := client.Runs.List(context.Background(), workspace.ID, &options)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs.Items) == 0 {
+		return nil, fmt.Errorf("workspace %q has no runs", ref)
+	}
+	return runs.Items[0], nil
+}
+
+// pollRun reads a run until the condition is met, backing off between the reads.
+func pollRun(ctx context.Context, client *tfe.Client, runID string, done func(*tfe.Run) bool) (*tfe.Run, error) {
+	delay := time.Second
+	for {
+		r, err := client.Runs.Read(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+		if done(r) {
+			return r, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return r, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		// Back off up to 15 seconds.
+		delay = delay * 3 / 2
+		if delay > 15*time.Second {
+			delay = 15 * time.Second
+		}
+	}
+}
+
+func printPlanLogs(client *tfe.Client, planID string, follow bool, w io.Writer) error {
+	if follow {
+		logs, err := client.Plans.Logs(context.Background(), planID)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, tfecli.NewLogFilter(logs))
+		return err
+	}
+
+	plan, err := client.Plans.Read(context.Background(), planID)
+	if err != nil {
+		return err
+	}
+	return copyLogs(plan.LogReadURL, w)
+}
+
+func printApplyLogs(client *tfe.Client, run *tfe.Run, follow bool, w io.Writer) error {
+	applyID := run.Apply.ID
+	if !follow {
+		apply, err := client.Applies.Read(context.Background(), applyID)
+		if err != nil {
+			return err
+		}
+		if apply.Status == tfe.ApplyPending || apply.Status == tfe.ApplyUnreachable {
+			return nil
+		}
+		return copyLogs(apply.LogReadURL, w)
+	}
+
+	// Wait for the apply to be confirmed, and return if the run completes or waits for
+	// a confirmation instead.
+	r, err := pollRun(context.Background(), client, run.ID, func(r *tfe.Run) bool {
+		switch r.Status {
+		case tfe.RunConfirmed, tfe.RunApplyQueued, tfe.RunApplying:
+			return true
+		}
+		// The runs applied automatically only wait for a policy override.
+		if tfecli.IsRunActionable(r.Status) {
+			return !r.AutoApply || r.Status == tfe.RunPolicyOverride
+		}
+		return tfecli.IsRunFinal(r.Status)
+	})
+	if err != nil {
+		return err
+	}
+	if tfecli.IsRunActionable(r.Status) {
+		return nil
+	}
+	if tfecli.IsRunFinal(r.Status) {
+		return printApplyLogs(client, r, false, w)
+	}
+
+	// Wait for the apply to start.
+	delay := time.Second
+	for {
+		apply, err := client.Applies.Read(context.Background(), applyID)
+		if err != nil {
+			return err
+		}
+		if apply.Status == tfe.ApplyUnreachable {
+			return nil
+		}
+		if apply.Status != tfe.ApplyPending {
+			break
+		}
+		time.Sleep(delay)
+		if delay < 15*time.Second {
+			delay = delay * 3 / 2
+		}
+	}
+
+	logs, err := client.Applies.Logs(context.Background(), applyID)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, tfecli.NewLogFilter(logs))
+	return err
+}
+
+// copyLogs copies the logs available at a log URL.
+func copyLogs(logURL string, w io.Writer) error {
+	if logURL == "" {
+		return nil
+	}
+	resp, err := http.Get(logURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot retrieve the logs: %s", resp.Status)
+	}
+	_, err = io.Copy(w, tfecli.NewLogFilter(resp.Body))
+	return err
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runPlanJSONCmd = &cobra.Command{
+	Use:   "plan-json [RUN_ID]",
+	Short: "Download the JSON execution plan of a run",
+	Long:  `Download the JSON execution plan of a run, once its plan is finished.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+		output, _ := cmd.Flags().GetString("output")
+
+		// Setup the command.
+		_, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the run.
+		run, err := client.Runs.Read(context.Background(), runID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve run %q: %s.", runID, err)
+		}
+
+		// Download the plan.
+		plan, err := client.Plans.ReadJSONOutput(context.Background(), run.Plan.ID)
+		if err != nil {
+			log.Fatalf("Cannot download the JSON plan of %q: %s.", runID, err)
+		}
+
+		// Write it.
+		if output == "" {
+			os.Stdout.Write(plan)
+			return
+		}
+		if err := ioutil.WriteFile(output, plan, 0600); err != nil {
+			log.Fatalf("Cannot write the JSON plan to %q: %s.", output, err)
+		}
+		log.Infof("JSON plan of %q written to %q.", runID, output)
+	},
+}
+
+var runSummaryCmd = &cobra.Command{
+	Use:   "summary [RUN_ID]",
+	Short: "Summarize the resource changes of a run",
+	Long: `Summarize the resource changes of a run by address and action.
+
+The deletions and replacements of protected resource types are flagged, and the
+command exits with code 6 if there are any. The protected types default to common
+resources holding data, like databases and buckets, and can be replaced with
+"--protected-type" or with a file listing one type per line. The types support
+wildcards, like "aws_db_*".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+		protectedTypes, err := readProtectedTypes(cmd)
+		if err != nil {
+			log.Fatalf("Cannot read the protected types: %s.", err)
+		}
+
+		// Setup the command.
+		_, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the run.
+		run, err := client.Runs.Read(context.Background(), runID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve run %q: %s.", runID, err)
+		}
+
+		// Retrieve the plan.
+		plan, err := readPlanJSON(client, run.Plan.ID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the JSON plan of %q: %s.", runID, err)
+		}
+		protected, err := plan.ProtectedChanges(protectedTypes)
+		if err != nil {
+			log.Fatalf("Cannot check the protected changes: %s.", err)
+		}
+		isProtected := map[string]bool{}
+		for _, r := range protected {
+			isProtected[r.Address] = true
+		}
+
+		// Print the changes.
+		changes := plan.ChangesByAction()
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ACTION\tADDRESS\t")
+		for _, action := range tfecli.PlanActions {
+			for _, address := range changes[action] {
+				flag := ""
+				if isProtected[address] {
+					flag = "PROTECTED"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", action, address, flag)
+			}
+		}
+		tw.Flush()
+
+		// Fail if protected resources would be deleted.
+		if len(protected) > 0 {
+			for _, r := range protected {
+				log.Errorf("Protected resource %q would be %sd.", r.Address, r.Action())
+			}
+			os.Exit(tfecli.ExitProtectedChanges)
+		}
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runPlanJSONCmd)
+	runCmd.AddCommand(runSummaryCmd)
+
+	runPlanJSONCmd.Flags().StringP("output", "O", "", "Write the JSON plan to a file instead of stdout")
+	runSummaryCmd.Flags().StringArray("protected-type", []string{}, "Specify a protected resource type (can be used multiple times)")
+	runSummaryCmd.Flags().String("protected-types-file", "", "Read the protected resource types from a file, one per line")
+}
+
+// readProtectedTypes reads the protected resource types from the flags, or returns the default ones.
+func readProtectedTypes(cmd *cobra.Command) ([]string, error) {
+	types, _ := cmd.Flags().GetStringArray("protected-type")
+	file, _ := cmd.Flags().GetString("protected-types-file")
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		patterns, err := tfecli.ReadPatterns(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %q: %s", file, err)
+		}
+		types = append(types, patterns...)
+	}
+	if len(types) == 0 {
+		return tfecli.DefaultProtectedTypes, nil
+	}
+	if err := tfecli.ValidateProtectedTypes(types); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runPoliciesCmd = &cobra.Command{
+	Use:   "policies [RUN_ID]",
+	Short: "List the policy results of a run",
+	Long: `List the result of each policy of each policy set checked during a run, with
+its enforcement level.
+
+With "--output junit", the results are printed as a JUnit XML report, with a test
+suite per policy set, for the CI systems. The advisory failures are reported as
+skipped tests. The enforcement levels are unknown when the token cannot read the
+policies of the organization.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "junit" {
+			log.Fatalf("Invalid output %q: must be text or junit.", output)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the enforcement levels of the policies, which are unknown when the
+		// token cannot read the policies of the organization.
+		levels, err := readEnforcementLevels(client, organization)
+		if err != nil {
+			if !isPermissionError(err) {
+				log.Fatalf("Cannot list the policies: %s.", err)
+			}
+			log.Warningf("Cannot list the policies of %q, the enforcement levels are unknown: %s.", organization, err)
+			levels = map[string]string{}
+		}
+
+		// Retrieve the policy results.
+		checks, err := listPolicyChecks(client, runID)
+		if err != nil {
+			log.Fatalf("Cannot list the policy checks of %q: %s.", runID, err)
+		}
+		results := []tfecli.PolicyResult{}
+		for _, c := range checks {
+			r, err := readPolicyResults(client, c.ID, levels)
+			if err != nil {
+				log.Fatalf("Cannot retrieve the results of policy check %q: %s.", c.ID, err)
+			}
+			results = append(results, r...)
+		}
+
+		// Print them.
+		if output == "junit" {
+			if err := tfecli.WritePolicyJUnit(os.Stdout, runID, results); err != nil {
+				log.Fatalf("Cannot print the policy results: %s.", err)
+			}
+			return
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "POLICY SET\tPOLICY\tENFORCEMENT\tRESULT")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.PolicySet, r.Policy, r.EnforcementLevel, r.Result)
+		}
+		tw.Flush()
+	},
+}
+
+var runPolicyOverrideCmd = &cobra.Command{
+	Use:   "policy-override [RUN_ID]",
+	Short: "Override the soft-mandatory policy failures of a run",
+	Long: `Override the soft-mandatory policy failures of a run.
+
+The permission of the token to override the policy checks is verified first, and the
+comment explaining the override is added to the run.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+		comment, _ := cmd.Flags().GetString("comment")
+
+		// Setup the command.
+		_, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the failed policy checks.
+		checks, err := listPolicyChecks(client, runID)
+		if err != nil {
+			log.Fatalf("Cannot list the policy checks of %q: %s.", runID, err)
+		}
+		failed := []*tfe.PolicyCheck{}
+		for _, c := range checks {
+			if c.Status == tfe.PolicyPasses || c.Status == tfe.PolicyOverridden {
+				continue
+			}
+			if err := tfecli.CheckPolicyOverride(c); err != nil {
+				log.Fatalf("Cannot override the policies of %q: %s.", runID, err)
+			}
+			failed = append(failed, c)
+		}
+		if len(failed) == 0 {
+			log.Fatalf("Cannot override the policies of %q: no policy check failed.", runID)
+		}
+
+		// Override the policy checks.
+		for _, c := range failed {
+			if _, err := client.PolicyChecks.Override(context.Background(), c.ID); err != nil {
+				log.Fatalf("Cannot override policy check %q: %s.", c.ID, err)
+			}
+		}
+
+		// Explain the override once it succeeded.
+		options := tfe.CommentCreateOptions{Body: comment}
+		if _, err := client.Comments.Create(context.Background(), runID, options); err != nil {
+			log.Fatalf("Cannot comment run %q: %s.", runID, err)
+		}
+		log.Infof("Policies of run %q overridden successfully.", runID)
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runPoliciesCmd)
+	runCmd.AddCommand(runPolicyOverrideCmd)
+
+	runPoliciesCmd.Flags().String("output", "text", "Specify the output format (text, junit)")
+	runPolicyOverrideCmd.Flags().String("comment", "", "Explain why the policies are overridden")
+	_ = runPolicyOverrideCmd.MarkFlagRequired("comment")
+}
+
+// readPolicyResults retrieves the result of each policy of a policy check.
+func readPolicyResults(client *tfe.Client, policyCheckID string, levels map[string]string) ([]tfecli.PolicyResult, error) {
+	// The details of the policy results are not exposed by go-tfe.
+	req, err := client.NewRequest("GET", fmt.Sprintf("policy-checks/%s", url.QueryEscape(policyCheckID)), nil)
+	if err != nil {
+		return nil, err
+	}
+	raw := &bytes.Buffer{}
+	if err := req.Do(context.Background(), raw); err != nil {
+		return nil, err
+	}
+	return tfecli.ParsePolicyCheck(raw.Bytes(), levels)
+}
+
+// readEnforcementLevels maps the names of the policies of an organization to their enforcement level.
+func readEnforcementLevels(client *tfe.Client, organization string) (map[string]string, error) {
+	levels := map[string]string{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.PolicyListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			},
+		}
+		p, err := client.Policies.List(context.Background(), organization, &options)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range p.Items {
+			if len(policy.Enforce) > 0 {
+				levels[policy.Name] = string(policy.Enforce[0].Mode)
+			}
+		}
+
+		// Check if there is another poage to retrieve.
+		if p.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return levels, nil
+}
+
+// isPermissionError reports whether a request was rejected with a 401 or a 404, the
+// latter being returned for the resources the token cannot read.
+func isPermissionError(err error) bool {
+	return errors.Is(err, tfe.ErrUnauthorized) || errors.Is(err, tfe.ErrResourceNotFound)
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// workspaceRunTriggerCmd represents the workspace run-trigger command.
+var workspaceRunTriggerCmd = &cobra.Command{
+	Use:   "run-trigger",
+	Short: "Manage TFE run triggers",
+	Long: `Manage TFE run triggers.
+
+Inbound run triggers are created by the source workspaces of a workspace, outbound
+run triggers are created by a workspace in the workspaces it is a source of.`,
+}
+
+var workspaceRunTriggerListCmd = &cobra.Command{
+	Use:   "list [WORKSPACE]",
+	Short: "List the run triggers of a workspace",
+	Long:  `List the run triggers of a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		inbound, _ := cmd.Flags().GetBool("inbound")
+		outbound, _ := cmd.Flags().GetBool("outbound")
+		if !inbound && !outbound {
+			inbound, outbound = true, true
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Print the run triggers.
+		if inbound {
+			triggers, err := listRunTriggers(client, workspace.ID, tfe.RunTriggerInbound)
+			if err != nil {
+				log.Fatalf("Cannot list the inbound run triggers for %q: %s.", name, err)
+			}
+			for _, t := range triggers {
+				fmt.Printf("inbound: %s -> %s\n", t.SourceableName, t.WorkspaceName)
+			}
+		}
+		if outbound {
+			triggers, err := listRunTriggers(client, workspace.ID, tfe.RunTriggerOutbound)
+			if err != nil {
+				log.Fatalf("Cannot list the outbound run triggers for %q: %s.", name, err)
+			}
+			for _, t := range triggers {
+				fmt.Printf("outbound: %s -> %s\n", t.SourceableName, t.WorkspaceName)
+			}
+		}
+	},
+}
+
+var workspaceRunTriggerAddCmd = &cobra.Command{
+	Use:   "add [WORKSPACE]",
+	Short: "Add run triggers to a workspace",
+	Long: `Add run triggers to a workspace.
+
+A run in a "--source" workspace queues a run in WORKSPACE, and a run in WORKSPACE
+queues a run in a "--target" workspace. All the workspaces must belong to the
+same organization.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		sources, _ := cmd.Flags().GetStringArray("source")
+		targets, _ := cmd.Flags().GetStringArray("target")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Retrieve the source and target workspaces.
+		sourceWorkspaces, err := readWorkspaceRefs(client, organization, sources)
+		if err != nil {
+			log.Fatalf("Cannot add run triggers to %q: %s.", name, err)
+		}
+		targetWorkspaces, err := readWorkspaceRefs(client, organization, targets)
+		if err != nil {
+			log.Fatalf("Cannot add run triggers to %q: %s.", name, err)
+		}
+
+		// Keep only the sources which are not already set up.
+		indexedSources, err := indexRunTriggers(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot index run triggers: %s.", err)
+		}
+		newSources, newSourceNames, seen := []*tfe.Workspace{}, []string{}, map[string]bool{}
+		for _, s := range sourceWorkspaces {
+			if _, exists := indexedSources[s.Name]; exists {
+				log.Infof("Run trigger %q -> %q already exists.", s.Name, name)
+				continue
+			}
+			if seen[s.Name] {
+				continue
+			}
+			seen[s.Name] = true
+			newSources = append(newSources, s)
+			newSourceNames = append(newSourceNames, s.Name)
+		}
+
+		// Validate the source limit before calling the API.
+		if err := tfecli.CheckRunTriggerSources(name, runTriggerSources(indexedSources), newSourceNames); err != nil {
+			log.Fatalf("Cannot add run triggers to %q: %s.", name, err)
+		}
+		newTargets := []*tfe.Workspace{}
+		for _, t := range targetWorkspaces {
+			indexedTargetSources, err := indexRunTriggers(client, t.ID)
+			if err != nil {
+				log.Fatalf("Cannot index run triggers: %s.", err)
+			}
+			if _, exists := indexedTargetSources[name]; exists {
+				log.Infof("Run trigger %q -> %q already exists.", name, t.Name)
+				continue
+			}
+			if err := tfecli.CheckRunTriggerSources(t.Name, runTriggerSources(indexedTargetSources), []string{name}); err != nil {
+				log.Fatalf("Cannot add run triggers to %q: %s.", name, err)
+			}
+			newTargets = append(newTargets, t)
+		}
+
+		// Create the inbound run triggers.
+		for _, s := range newSources {
+			if _, err := createRunTrigger(client, workspace, s); err != nil {
+				log.Fatalf("Cannot create run trigger %q -> %q: %s.", s.Name, name, err)
+			}
+			log.Infof("Run trigger %q -> %q created successfully.", s.Name, name)
+		}
+
+		// Create the outbound run triggers.
+		for _, t := range newTargets {
+			if _, err := createRunTrigger(client, t, workspace); err != nil {
+				log.Fatalf("Cannot create run trigger %q -> %q: %s.", name, t.Name, err)
+			}
+			log.Infof("Run trigger %q -> %q created successfully.", name, t.Name)
+		}
+	},
+}
+
+var workspaceRunTriggerRemoveCmd = &cobra.Command{
+	Use:   "remove [WORKSPACE]",
+	Short: "Remove run triggers from a workspace",
+	Long:  `Remove run triggers from a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		sources, _ := cmd.Flags().GetStringArray("source")
+		targets, _ := cmd.Flags().GetStringArray("target")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Remove the inbound run triggers.
+		indexedSources, err := indexRunTriggers(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot index run triggers: %s.", err)
+		}
+		for _, ref := range sources {
+			source, err := tfecli.ParseWorkspaceRef(ref, organization)
+			if err != nil {
+				log.Fatalf("Cannot remove run triggers from %q: %s.", name, err)
+			}
+			trigger, exists := indexedSources[source]
+			if !exists {
+				log.Warningf("Cannot delete run trigger %q -> %q: it does not exist.", source, name)
+				continue
+			}
+			if err := client.RunTriggers.Delete(context.Background(), trigger.ID); err != nil {
+				log.Fatalf("Cannot delete run trigger %q -> %q: %s.", source, name, err)
+			}
+			log.Infof("Run trigger %q -> %q deleted successfully.", source, name)
+		}
+
+		// Remove the outbound run triggers.
+		targetWorkspaces, err := readWorkspaceRefs(client, organization, targets)
+		if err != nil {
+			log.Fatalf("Cannot remove run triggers from %q: %s.", name, err)
+		}
+		for _, t := range targetWorkspaces {
+			indexedTargetSources, err := indexRunTriggers(client, t.ID)
+			if err != nil {
+				log.Fatalf("Cannot index run triggers: %s.", err)
+			}
+			trigger, exists := indexedTargetSources[name]
+			if !exists {
+				log.Warningf("Cannot delete run trigger %q -> %q: it does not exist.", name, t.Name)
+				continue
+			}
+			if err := client.RunTriggers.Delete(context.Background(), trigger.ID); err != nil {
+				log.Fatalf("Cannot delete run trigger %q -> %q: %s.", name, t.Name, err)
+			}
+			log.Infof("Run trigger %q -> %q deleted successfully.", name, t.Name)
+		}
+	},
+}
+
+func init() {
+	workspaceCmd.AddCommand(workspaceRunTriggerCmd)
+	workspaceRunTriggerCmd.AddCommand(workspaceRunTriggerListCmd)
+	workspaceRunTriggerCmd.AddCommand(workspaceRunTriggerAddCmd)
+	workspaceRunTriggerCmd.AddCommand(workspaceRunTriggerRemoveCmd)
+
+	workspaceRunTriggerListCmd.Flags().Bool("inbound", false, "Only list the inbound run triggers")
+	workspaceRunTriggerListCmd.Flags().Bool("outbound", false, "Only list the outbound run triggers")
+
+	workspaceRunTriggerAddCmd.Flags().StringArray("source", []string{}, "Specify a workspace triggering runs in this workspace")
+	workspaceRunTriggerAddCmd.Flags().StringArray("target", []string{}, "Specify a workspace in which this workspace triggers runs")
+
+	workspaceRunTriggerRemoveCmd.Flags().StringArray("source", []string{}, "Specify a workspace triggering runs in this workspace")
+	workspaceRunTriggerRemoveCmd.Flags().StringArray("target", []string{}, "Specify a workspace in which this workspace triggers runs")
+}
+
+// readWorkspaceRefs retrieves the workspaces matching `[organization/]workspace` references.
+func readWorkspaceRefs(client *tfe.Client, organization string, refs []string) ([]*tfe.Workspace, error) {
+	workspaces := []*tfe.Workspace{}
+	for _, ref := range refs {
+		name, err := tfecli.ParseWorkspaceRef(ref, organization)
+		if err != nil {
+			return nil, err
+		}
+		w, err := readWorkspace(client, organization, name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve workspace %q: %s", name, err)
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, nil
+}
+
+func listRunTriggers(client *tfe.Client, workspaceID string, triggerType tfe.RunTriggerFilterOp) ([]*tfe.RunTrigger, error) {
+	results := []*tfe.RunTrigger{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.RunTriggerListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			},
+			RunTriggerType: triggerType,
+		}
+		t, err := client.RunTriggers.List(context.Background(), workspaceID, &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, t.Items...)
+
+		// Check if there is another poage to retrieve.
+		if t.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+// indexRunTriggers indexes the inbound run triggers of a workspace by source workspace name.
+func indexRunTriggers(client *tfe.Client, workspaceID string) (map[string]*tfe.RunTrigger, error) {
+	// List existing run triggers.
+	triggers, err := listRunTriggers(client, workspaceID, tfe.RunTriggerInbound)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the run triggers for %q: %s", workspaceID, err)
+	}
+
+	// Index them by source workspace name.
+	indexedTriggers := map[string]*tfe.RunTrigger{}
+	for _, t := range triggers {
+		indexedTriggers[t.SourceableName] = t
+	}
+
+	return indexedTriggers, nil
+}
+
+// runTriggerSources returns the names of the source workspaces of indexed run triggers.
+func runTriggerSources(indexedTriggers map[string]*tfe.RunTrigger) []string {
+	sources := []string{}
+	for source := range indexedTriggers {
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+func createRunTrigger(client *tfe.Client, workspace, source *tfe.Workspace) (*tfe.RunTrigger, error) {
+	options := tfe.RunTriggerCreateOptions{
+		Sourceable: source,
+	}
+	t, err := client.RunTriggers.Create(context.Background(), workspace.ID, options)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
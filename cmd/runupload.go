@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runUploadCmd = &cobra.Command{
+	Use:   "upload [WORKSPACE]",
+	Short: "Upload a local configuration and run it",
+	Long: `Upload a local configuration and run it.
+
+The directory is packed into an archive honoring the .terraformignore file, uploaded
+as a new configuration version, and a run is queued and followed. The run accepts
+the same flags as "run start", and the command exits with the same codes as
+"run logs".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		dir, _ := cmd.Flags().GetString("dir")
+		speculative, _ := cmd.Flags().GetBool("speculative")
+		noFollow, _ := cmd.Flags().GetBool("no-follow")
+		options, err := readRunCreateOptions(cmd)
+		if err != nil {
+			log.Fatalf("Cannot queue the run: %s.", err)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Upload the configuration.
+		cv, err := uploadConfiguration(client, workspace, dir, speculative)
+		if err != nil {
+			log.Fatalf("Cannot upload the configuration of %q: %s.", name, err)
+		}
+
+		// Queue the run.
+		options.ConfigurationVersion = cv
+		run, err := startRun(client, workspace, options)
+		if err != nil {
+			log.Fatalf("Cannot queue a run in %q: %s.", name, err)
+		}
+		fmt.Fprintf(os.Stderr, "%s %s\n", run.ID, tfecli.RunURL(organization, name, run.ID))
+		if noFollow {
+			return
+		}
+
+		// Follow it.
+		runID := run.ID
+		run, err = printRunLogs(client, run, "", true, os.Stdout)
+		if err != nil {
+			log.Fatalf("Cannot print the logs of %q: %s.", runID, err)
+		}
+
+		// Exit with the status of the run.
+		log.Infof("Run %q is %s.", run.ID, run.Status)
+		os.Exit(tfecli.RunExitCode(run.Status, run.HasChanges))
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runUploadCmd)
+
+	addRunCreateFlags(runUploadCmd)
+	runUploadCmd.Flags().String("dir", ".", "Specify the directory containing the configuration")
+	runUploadCmd.Flags().Bool("speculative", false, "Upload a speculative configuration, which can only be planned")
+	runUploadCmd.Flags().Bool("no-follow", false, "Do not follow the run")
+}
+
+// uploadConfiguration uploads a directory as a new configuration version of a workspace,
+// and waits for it to be processed.
+func uploadConfiguration(client *tfe.Client, workspace *tfe.Workspace, dir string, speculative bool) (*tfe.ConfigurationVersion, error) {
+	// Create the configuration version.
+	options := tfe.ConfigurationVersionCreateOptions{
+		AutoQueueRuns: tfe.Bool(false),
+		Speculative:   tfe.Bool(speculative),
+	}
+	cv, err := client.ConfigurationVersions.Create(context.Background(), workspace.ID, options)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the configuration version: %s", err)
+	}
+
+	// Pack and upload the directory.
+	log.Debugf("Uploading %q to configuration version %q.", dir, cv.ID)
+	if err := client.ConfigurationVersions.Upload(context.Background(), cv.UploadURL, dir); err != nil {
+		return nil, fmt.Errorf("cannot upload %q: %s", dir, err)
+	}
+
+	// Wait for the configuration version to be processed.
+	cvID := cv.ID
+	delay := time.Second
+	for {
+		cv, err = client.ConfigurationVersions.Read(context.Background(), cvID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve configuration version %q: %s", cvID, err)
+		}
+		switch cv.Status {
+		case tfe.ConfigurationUploaded:
+			return cv, nil
+		case tfe.ConfigurationErrored:
+			return nil, fmt.Errorf("configuration version %q errored: %s", cv.ID, cv.ErrorMessage)
+		}
+		time.Sleep(delay)
+		if delay < 5*time.Second {
+			delay *= 2
+		}
+	}
+}
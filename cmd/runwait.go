@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runWaitCmd = &cobra.Command{
+	Use:   "wait [RUN_ID]",
+	Short: "Wait for a run to complete",
+	Long: `Wait for a run to complete or to require an action, like a confirmation or a
+policy override, and print its status transitions.
+
+With "--until planned", the command returns as soon as the plan is complete. With
+"--until applied", it keeps waiting while the run waits for a confirmation.
+
+The command exits with a code reflecting the status of the run:
+  0: the run was applied
+  2: the plan has changes which are not applied
+  3: the plan has no changes
+  4: the run errored, or was canceled or discarded
+  5: the timeout expired`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		runID := args[0]
+		until, _ := cmd.Flags().GetString("until")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		// Select the statuses to wait for.
+		var done func(*tfe.Run) bool
+		switch until {
+		case "":
+			done = func(r *tfe.Run) bool { return tfecli.IsRunFinal(r.Status) || tfecli.IsRunActionable(r.Status) }
+		case "planned":
+			done = func(r *tfe.Run) bool {
+				return tfecli.IsRunFinal(r.Status) || tfecli.IsRunActionable(r.Status) || r.Status == tfe.RunConfirmed || r.Status == tfe.RunApplyQueued || r.Status == tfe.RunApplying
+			}
+		case "applied":
+			done = func(r *tfe.Run) bool { return tfecli.IsRunFinal(r.Status) }
+		default:
+			log.Fatalf("Invalid status %q: must be planned or applied.", until)
+		}
+
+		// Setup the command.
+		_, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Stop waiting after the timeout.
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// Wait for the run, printing the status transitions.
+		start := time.Now()
+		lastChange := start
+		var lastStatus tfe.RunStatus
+		run, err := pollRun(ctx, client, runID, func(r *tfe.Run) bool {
+			if r.Status != lastStatus {
+				now := time.Now()
+				if lastStatus == "" {
+					fmt.Printf("%s\n", r.Status)
+				} else {
+					fmt.Printf("%s -> %s (%s)\n", lastStatus, r.Status, now.Sub(lastChange).Round(time.Second))
+				}
+				lastStatus, lastChange = r.Status, now
+			}
+			return done(r)
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Errorf("Run %q is still %s after %s.", runID, lastStatus, timeout)
+			os.Exit(tfecli.ExitTimeout)
+		}
+		if err != nil {
+			log.Fatalf("Cannot retrieve run %q: %s.", runID, err)
+		}
+
+		// Exit with the status of the run.
+		log.Infof("Run %q is %s after %s.", run.ID, run.Status, time.Since(start).Round(time.Second))
+		if r := run.Status; until == "planned" && (r == tfe.RunConfirmed || r == tfe.RunApplyQueued || r == tfe.RunApplying) {
+			os.Exit(tfecli.ExitChangesPending)
+		}
+		os.Exit(tfecli.RunExitCode(run.Status, run.HasChanges))
+	},
+}
+
+func init() {
+	runCmd.AddCommand(runWaitCmd)
+
+	runWaitCmd.Flags().String("until", "", "Wait until the run is planned or applied")
+	runWaitCmd.Flags().Duration("timeout", 0, "Stop waiting after this duration, e.g. 30m (0 waits forever)")
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// stateCmd represents the state command.
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage TFE state versions",
+	Long:  `Manage TFE state versions.`,
+}
+
+var stateListCmd = &cobra.Command{
+	Use:   "list [WORKSPACE]",
+	Short: "List the state versions of a workspace",
+	Long:  `List the state versions of a workspace, most recent first.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// List the state versions.
+		stateVersions, err := listStateVersions(client, organization, name, limit)
+		if err != nil {
+			log.Fatalf("Cannot list the state versions for %q: %s.", name, err)
+		}
+
+		// Retrieve the creators from the runs.
+		creators := make([]string, len(stateVersions))
+		var eg errgroup.Group
+		for i, sv := range stateVersions {
+			i, sv := i, sv
+			creators[i] = "-"
+			if sv.Run == nil {
+				continue
+			}
+			eg.Go(func() error {
+				options := tfe.RunReadOptions{Include: []tfe.RunIncludeOpt{tfe.RunCreatedBy}}
+				r, err := client.Runs.ReadWithOptions(context.Background(), sv.Run.ID, &options)
+				if err != nil {
+					return fmt.Errorf("cannot retrieve run %q: %s", sv.Run.ID, err)
+				}
+				if r.CreatedBy != nil {
+					creators[i] = r.CreatedBy.Username
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			log.Fatalf("%s.", err)
+		}
+
+		// Print the state versions.
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SERIAL\tRUN\tCREATED AT\tCREATOR")
+		for i, sv := range stateVersions {
+			run := "-"
+			if sv.Run != nil {
+				run = sv.Run.ID
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", sv.Serial, run, sv.CreatedAt.Format(time.RFC3339), creators[i])
+		}
+		tw.Flush()
+	},
+}
+
+var statePullCmd = &cobra.Command{
+	Use:   "pull [WORKSPACE]",
+	Short: "Download the state of a workspace",
+	Long:  `Download the state of a workspace, the current one unless a serial is specified.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		serial, _ := cmd.Flags().GetInt64("serial")
+		output, _ := cmd.Flags().GetString("output")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Download the state.
+		state, err := downloadState(client, organization, name, serial)
+		if err != nil {
+			log.Fatalf("Cannot download the state of %q: %s.", name, err)
+		}
+
+		// Write it.
+		if output == "" {
+			os.Stdout.Write(state)
+			return
+		}
+		if err := ioutil.WriteFile(output, state, 0600); err != nil {
+			log.Fatalf("Cannot write the state to %q: %s.", output, err)
+		}
+		log.Infof("State of %q written to %q.", name, output)
+	},
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show [WORKSPACE]",
+	Short: "Summarize the state of a workspace",
+	Long:  `Summarize the providers, resources and outputs of the state of a workspace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		serial, _ := cmd.Flags().GetInt64("serial")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Download the state.
+		raw, err := downloadState(client, organization, name, serial)
+		if err != nil {
+			log.Fatalf("Cannot download the state of %q: %s.", name, err)
+		}
+
+		// Summarize it.
+		state, err := tfecli.ParseState(raw)
+		if err != nil {
+			log.Fatalf("Cannot parse the state of %q: %s.", name, err)
+		}
+		if err := state.WriteSummary(os.Stdout); err != nil {
+			log.Fatalf("Cannot print the state summary: %s.", err)
+		}
+	},
+}
+
+var stateRollbackCmd = &cobra.Command{
+	Use:   "rollback [WORKSPACE]",
+	Short: "Restore a previous state version",
+	Long: `Restore a previous state version.
+
+The workspace is locked while the previous state version is uploaded again as the
+new current state.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		serial, _ := cmd.Flags().GetInt64("to-serial")
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+		if workspace.Locked {
+			log.Fatalf("Cannot roll back the state of %q: the workspace is locked.", name)
+		}
+
+		// Retrieve the current state version and the one to restore.
+		current, err := client.StateVersions.ReadCurrent(context.Background(), workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the current state version of %q: %s.", name, err)
+		}
+		if current.Serial == serial {
+			log.Fatalf("Cannot roll back the state of %q: serial %d is already the current state.", name, serial)
+		}
+		target, err := readStateVersionBySerial(client, organization, name, serial)
+		if err != nil {
+			log.Fatalf("Cannot retrieve the state version of %q: %s.", name, err)
+		}
+
+		// Lock the workspace.
+		options := tfe.WorkspaceLockOptions{
+			Reason: tfe.String(fmt.Sprintf("Rolling back the state to serial %d", serial)),
+		}
+		if _, err := client.Workspaces.Lock(context.Background(), workspace.ID, options); err != nil {
+			log.Fatalf("Cannot lock workspace %q: %s.", name, err)
+		}
+
+		// Upload the previous state as the new current state, then unlock the workspace.
+		err = restoreStateVersion(client, workspace.ID, target, current.Serial+1)
+		if _, unlockErr := client.Workspaces.Unlock(context.Background(), workspace.ID); unlockErr != nil {
+			log.Errorf("Cannot unlock workspace %q: %s.", name, unlockErr)
+		}
+		if err != nil {
+			log.Fatalf("Cannot roll back the state of %q: %s.", name, err)
+		}
+
+		log.Infof("State of %q rolled back to serial %d as serial %d.", name, serial, current.Serial+1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateListCmd)
+	stateCmd.AddCommand(statePullCmd)
+	stateCmd.AddCommand(stateShowCmd)
+	stateCmd.AddCommand(stateRollbackCmd)
+
+	stateListCmd.Flags().Int("limit", 20, "Specify the maximum number of state versions to list (0 for all)")
+	statePullCmd.Flags().Int64("serial", -1, "Specify the serial of the state version (defaults to the current one)")
+	statePullCmd.Flags().StringP("output", "O", "", "Write the state to a file instead of stdout")
+	stateShowCmd.Flags().Int64("serial", -1, "Specify the serial of the state version (defaults to the current one)")
+	stateRollbackCmd.Flags().Int64("to-serial", 0, "Specify the serial of the state version to restore")
+	_ = stateRollbackCmd.MarkFlagRequired("to-serial")
+}
+
+// listStateVersions lists the state versions of a workspace, most recent first.
+// A limit of 0 lists all of them.
+func listStateVersions(client *tfe.Client, organization, workspace string, limit int) ([]*tfe.StateVersion, error) {
+	results := []*tfe.StateVersion{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.StateVersionListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			},
+			Organization: organization,
+			Workspace:    workspace,
+		}
+		s, err := client.StateVersions.List(context.Background(), &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, s.Items...)
+
+		// Stop when enough state versions were retrieved.
+		if limit > 0 && len(results) >= limit {
+			return results[:limit], nil
+		}
+
+		// Check if there is another poage to retrieve.
+		if s.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+func readStateVersionBySerial(client *tfe.Client, organization, workspace string, serial int64) (*tfe.StateVersion, error) {
+	stateVersions, err := listStateVersions(client, organization, workspace, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, sv := range stateVersions {
+		if sv.Serial == serial {
+			return sv, nil
+		}
+	}
+	return nil, fmt.Errorf("no state version with serial %d", serial)
+}
+
+// downloadState downloads a state version of a workspace. A negative serial downloads the current state.
+func downloadState(client *tfe.Client, organization, name string, serial int64) ([]byte, error) {
+	var sv *tfe.StateVersion
+	if serial < 0 {
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve workspace %q: %s", name, err)
+		}
+		sv, err = client.StateVersions.ReadCurrent(context.Background(), workspace.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve the current state version: %s", err)
+		}
+	} else {
+		var err error
+		sv, err = readStateVersionBySerial(client, organization, name, serial)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client.StateVersions.Download(context.Background(), sv.DownloadURL)
+}
+
+// restoreStateVersion uploads the content of a state version as a new state version with the given serial.
+func restoreStateVersion(client *tfe.Client, workspaceID string, sv *tfe.StateVersion, serial int64) error {
+	raw, err := client.StateVersions.Download(context.Background(), sv.DownloadURL)
+	if err != nil {
+		return fmt.Errorf("cannot download state version %q: %s", sv.ID, err)
+	}
+	state, err := tfecli.ParseState(raw)
+	if err != nil {
+		return err
+	}
+	raw, err = tfecli.SetStateSerial(raw, serial)
+	if err != nil {
+		return err
+	}
+
+	options := tfe.StateVersionCreateOptions{
+		Lineage: tfe.String(state.Lineage),
+		MD5:     tfe.String(fmt.Sprintf("%x", md5.Sum(raw))),
+		Serial:  tfe.Int64(serial),
+		State:   tfe.String(base64.StdEncoding.EncodeToString(raw)),
+	}
+	if _, err := client.StateVersions.Create(context.Background(), workspaceID, options); err != nil {
+		return fmt.Errorf("cannot create the state version: %s", err)
+	}
+	return nil
+}
@@ -3,8 +3,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	tfe "github.com/hashicorp/go-tfe"
 	"github.com/rgreinho/tfe-cli/tfecli"
 	log "github.com/sirupsen/logrus"
@@ -12,6 +16,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// valueResolver reads the values of the variables given on the command line.
+var valueResolver = tfecli.NewValueResolver()
+
 // variablesCmd represents the variables command
 var variableCmd = &cobra.Command{
 	Use:   "variable",
@@ -34,7 +41,7 @@ var variableCreateCmd = &cobra.Command{
 		EnvVars, _ := cmd.Flags().GetStringArray("evar")
 		sEnvVars, _ := cmd.Flags().GetStringArray("sevar")
 		force, _ := cmd.Flags().GetBool("force")
-		varFiles, _ := cmd.Flags().GetStringArray("var-file")
+		resolve, _ := cmd.Flags().GetBool("resolve")
 
 		// Setup the command.
 		organization, client, err := tfecli.Setup(cmd)
@@ -50,32 +57,21 @@ var variableCreateCmd = &cobra.Command{
 
 		// Prepare the variables.
 		varOptions := []tfe.VariableCreateOptions{}
-		varOptions = append(varOptions, createVariableOptions(vars, tfe.CategoryTerraform, false, false)...)
-		varOptions = append(varOptions, createVariableOptions(svars, tfe.CategoryTerraform, false, true)...)
-		varOptions = append(varOptions, createVariableOptions(HCLvars, tfe.CategoryTerraform, true, false)...)
-		varOptions = append(varOptions, createVariableOptions(sHCLVars, tfe.CategoryTerraform, true, true)...)
-		varOptions = append(varOptions, createVariableOptions(EnvVars, tfe.CategoryEnv, false, false)...)
-		varOptions = append(varOptions, createVariableOptions(sEnvVars, tfe.CategoryEnv, false, true)...)
-
-		// Read variables from file.
-		for _, varFile := range varFiles {
-			// Parse the varfile.
-			HCLvars, err := tfecli.ParseVarFile(varFile)
-			if err != nil {
-				log.Fatalf("Cannot read the file %q: %s.", varFile, err)
-			}
-
-			// Convert the content to `key=value` format.
-			HCLVarFile := []string{}
-			for _, v := range HCLvars {
-				HCLVarFile = append(HCLVarFile, v.String())
-			}
-
-			// Add it to the list of variables to create.
-			varOptions = append(varOptions, createVariableOptions(HCLVarFile, tfe.CategoryTerraform, true, false)...)
+		varOptions = append(varOptions, createVariableOptions(vars, tfe.CategoryTerraform, false, false, resolve)...)
+		varOptions = append(varOptions, createVariableOptions(svars, tfe.CategoryTerraform, false, true, resolve)...)
+		varOptions = append(varOptions, createVariableOptions(HCLvars, tfe.CategoryTerraform, true, false, resolve)...)
+		varOptions = append(varOptions, createVariableOptions(sHCLVars, tfe.CategoryTerraform, true, true, resolve)...)
+		varOptions = append(varOptions, createVariableOptions(EnvVars, tfe.CategoryEnv, false, false, resolve)...)
+		varOptions = append(varOptions, createVariableOptions(sEnvVars, tfe.CategoryEnv, false, true, resolve)...)
+
+		// Read variables from files.
+		fileOptions, err := readVariableFiles(cmd)
+		if err != nil {
+			log.Fatalf("Cannot read the variable files: %s.", err)
 		}
+		varOptions = append(varOptions, fileOptions...)
 
-		// List existing variables and index them by key.
+		// List existing variables and index them by category and key.
 		indexedVars, err := indexVariables(client, workspace.ID, organization)
 		if err != nil {
 			log.Fatalf("Cannot index variables: %s.", err)
@@ -86,7 +82,7 @@ var variableCreateCmd = &cobra.Command{
 		for _, options := range varOptions {
 			opts := options
 			//Check if the variable already exists.
-			v, exists := indexedVars[*(opts.Key)]
+			v, exists := indexedVars[tfecli.VariableID(*opts.Key, *opts.Category)]
 			variableID := ""
 			if exists {
 				variableID = v.ID
@@ -150,6 +146,10 @@ var variableDeleteCmd = &cobra.Command{
 		// Read the flags.
 		wsName := args[0]
 		varName := args[1]
+		category, _ := cmd.Flags().GetString("category")
+		if category != "" && category != string(tfe.CategoryTerraform) && category != string(tfe.CategoryEnv) {
+			log.Fatalf("Invalid category %q: must be %q or %q.", category, tfe.CategoryTerraform, tfe.CategoryEnv)
+		}
 
 		// Setup the command.
 		organization, client, err := tfecli.Setup(cmd)
@@ -163,18 +163,31 @@ var variableDeleteCmd = &cobra.Command{
 			log.Fatalf("Cannot retrieve workspace %q: %s.", wsName, err)
 		}
 
-		// List existing variables and index them by key.
+		// List existing variables and index them by category and key.
 		indexedVars, err := indexVariables(client, workspace.ID, organization)
 		if err != nil {
 			log.Fatalf("Cannot index variables: %s.", err)
 		}
 
-		// Check if it exists.
-		v, exists := indexedVars[varName]
-		if !exists {
+		// Check if it exists, in the requested category or in any category.
+		categories := []tfe.CategoryType{tfe.CategoryTerraform, tfe.CategoryEnv}
+		if category != "" {
+			categories = []tfe.CategoryType{tfe.CategoryType(category)}
+		}
+		matches := []*tfe.Variable{}
+		for _, c := range categories {
+			if v, exists := indexedVars[tfecli.VariableID(varName, c)]; exists {
+				matches = append(matches, v)
+			}
+		}
+		if len(matches) == 0 {
 			log.Warningf("Cannot delete variable %q: it does not exist.", varName)
 			return
 		}
+		if len(matches) > 1 {
+			log.Fatalf("Cannot delete variable %q: it is both a Terraform and an environment variable, specify its --category.", varName)
+		}
+		v := matches[0]
 
 		// And delete it if it does.
 		if err := deleteVariable(client, workspace.ID, v.ID); err != nil {
@@ -196,8 +209,10 @@ func init() {
 	variableCreateCmd.Flags().StringArray("shvar", []string{}, "Create a sensitive HCL variable")
 	variableCreateCmd.Flags().StringArray("evar", []string{}, "Create an environment variable")
 	variableCreateCmd.Flags().StringArray("sevar", []string{}, "Create a sensitive environment variable")
-	variableCreateCmd.Flags().StringArray("var-file", []string{}, "Create non-sensitive regular and HCL variables from a file")
+	variableCreateCmd.Flags().Bool("resolve", false, "Read the values of the variables from their source: @file, - (stdin), env:NAME or cmd:COMMAND")
+	addVariableFileFlags(variableCreateCmd)
 	variableCreateCmd.Flags().BoolP("force", "f", false, "Overwrite a variable if it exists")
+	variableDeleteCmd.Flags().String("category", "", "Delete the variable of a category: terraform or env")
 }
 
 func createVariable(client *tfe.Client, workspaceID string, options tfe.VariableCreateOptions) (*tfe.Variable, error) {
@@ -219,7 +234,7 @@ func listVariables(client *tfe.Client, workspaceID string) ([]*tfe.Variable, err
 			ListOptions: tfe.ListOptions{
 				PageNumber: currentPage,
 			}}
-		v, err := client.Variables.List(context.Background(), workspaceID, options)
+		v, err := client.Variables.List(context.Background(), workspaceID, &options)
 		if err != nil {
 			return nil, err
 		}
@@ -237,17 +252,27 @@ func listVariables(client *tfe.Client, workspaceID string) ([]*tfe.Variable, err
 	return results, nil
 }
 
-func createVariableOptions(vars []string, category tfe.CategoryType, hcl, sensitive bool) []tfe.VariableCreateOptions {
+// createVariableOptions prepares the creation of variables given as key=value. When
+// resolve is true, the value can be read from a file, the standard input, an environment
+// variable or a command (see tfecli.ValueResolver). It is never logged.
+func createVariableOptions(vars []string, category tfe.CategoryType, hcl, sensitive, resolve bool) []tfe.VariableCreateOptions {
 	optionList := []tfe.VariableCreateOptions{}
 
 	for _, v := range vars {
 		splitV := strings.SplitN(v, "=", 2)
 		if len(splitV) != 2 {
-			log.Fatalf("Invalid variable %q: the format must be key=value. %d", v, len(splitV))
+			log.Fatalf("Invalid variable %q: the format must be key=value.", splitV[0])
+		}
+		value := splitV[1]
+		if resolve {
+			var err error
+			if value, err = valueResolver.Resolve(value); err != nil {
+				log.Fatalf("Cannot read the value of variable %q: %s.", splitV[0], err)
+			}
 		}
 		options := tfe.VariableCreateOptions{
 			Key:       tfe.String(splitV[0]),
-			Value:     tfe.String(splitV[1]),
+			Value:     tfe.String(value),
 			Category:  tfe.Category(category),
 			HCL:       tfe.Bool(hcl),
 			Sensitive: tfe.Bool(sensitive),
@@ -258,6 +283,129 @@ func createVariableOptions(vars []string, category tfe.CategoryType, hcl, sensit
 	return optionList
 }
 
+// addVariableFileFlags adds the flags reading variables from files.
+func addVariableFileFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("var-file", []string{}, "Create non-sensitive regular and HCL variables from a file (.tfvars, .tfvars.json or .yaml)")
+	cmd.Flags().StringArray("svar-file", []string{}, "Create sensitive regular and HCL variables from a file (.tfvars, .tfvars.json or .yaml)")
+	cmd.Flags().StringArray("env-file", []string{}, "Create environment variables from a dotenv file")
+	cmd.Flags().StringArray("senv-file", []string{}, "Create sensitive environment variables from a dotenv file")
+	cmd.Flags().String("var-map", "", "Mark the variables of the files as sensitive, HCL, env or terraform by key")
+	cmd.Flags().StringArray("age-identity", []string{}, "Decrypt the files encrypted with SOPS using the age identities of a file (default $SOPS_AGE_KEY_FILE)")
+}
+
+// variableFiles returns the files given by the flags added by addVariableFileFlags.
+func variableFiles(cmd *cobra.Command) []string {
+	files := []string{}
+	for _, flag := range []string{"var-file", "svar-file", "env-file", "senv-file"} {
+		values, _ := cmd.Flags().GetStringArray(flag)
+		files = append(files, values...)
+	}
+	return files
+}
+
+// readVariableFiles reads the variables from the files given by the flags added by addVariableFileFlags.
+func readVariableFiles(cmd *cobra.Command) ([]tfe.VariableCreateOptions, error) {
+	varFiles, _ := cmd.Flags().GetStringArray("var-file")
+	sVarFiles, _ := cmd.Flags().GetStringArray("svar-file")
+	envFiles, _ := cmd.Flags().GetStringArray("env-file")
+	sEnvFiles, _ := cmd.Flags().GetStringArray("senv-file")
+	varMap, _ := cmd.Flags().GetString("var-map")
+
+	// Read the mapping file.
+	mapping := &tfecli.VariableMapping{}
+	if varMap != "" {
+		var err error
+		if mapping, err = tfecli.ReadVariableMapping(varMap); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the files.
+	sources := []struct {
+		files     []string
+		parse     func([]byte, string) ([]tfecli.Variable, error)
+		category  tfe.CategoryType
+		sensitive bool
+	}{
+		{varFiles, tfecli.ParseVarData, tfe.CategoryTerraform, false},
+		{sVarFiles, tfecli.ParseVarData, tfe.CategoryTerraform, true},
+		{envFiles, tfecli.ParseDotenv, tfe.CategoryEnv, false},
+		{sEnvFiles, tfecli.ParseDotenv, tfe.CategoryEnv, true},
+	}
+	var identities []age.Identity
+	optionList := []tfe.VariableCreateOptions{}
+	for _, source := range sources {
+		for _, file := range source.files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read the file %q: %s", file, err)
+			}
+
+			// Decrypt the files encrypted with SOPS.
+			var fileVars []tfecli.Variable
+			if tfecli.IsSOPSFile(data, file) {
+				if identities == nil {
+					if identities, err = readAgeIdentities(cmd); err != nil {
+						return nil, err
+					}
+				}
+				fileVars, err = tfecli.DecryptSOPSVars(data, file, identities, source.parse)
+			} else {
+				fileVars, err = source.parse(data, file)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if fileVars, err = mapping.Apply(fileVars); err != nil {
+				return nil, fmt.Errorf("%s: %s", file, err)
+			}
+			optionList = append(optionList, fileVariableOptions(fileVars, source.category, source.sensitive)...)
+		}
+	}
+	return optionList, nil
+}
+
+// readAgeIdentities reads the age identities from the files given by the "--age-identity"
+// flag, from the file pointed by SOPS_AGE_KEY_FILE, or from the default location of SOPS.
+func readAgeIdentities(cmd *cobra.Command) ([]age.Identity, error) {
+	files, _ := cmd.Flags().GetStringArray("age-identity")
+	if len(files) == 0 {
+		if file := os.Getenv("SOPS_AGE_KEY_FILE"); file != "" {
+			files = append(files, file)
+		} else if dir, err := os.UserConfigDir(); err == nil {
+			file := filepath.Join(dir, "sops", "age", "keys.txt")
+			if _, err := os.Stat(file); err == nil {
+				files = append(files, file)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cannot decrypt the files encrypted with SOPS: specify an age identity with --age-identity or SOPS_AGE_KEY_FILE")
+	}
+	return tfecli.ReadAgeIdentities(files)
+}
+
+// fileVariableOptions prepares the creation of variables read from a file, keeping their
+// flags. The category applies to the variables without one.
+func fileVariableOptions(vars []tfecli.Variable, category tfe.CategoryType, sensitive bool) []tfe.VariableCreateOptions {
+	optionList := []tfe.VariableCreateOptions{}
+	for _, v := range vars {
+		c := category
+		if v.Category != "" {
+			c = v.Category
+		}
+		options := tfe.VariableCreateOptions{
+			Key:       tfe.String(v.Key),
+			Value:     tfe.String(v.Value),
+			Category:  tfe.Category(c),
+			HCL:       tfe.Bool(v.HCL),
+			Sensitive: tfe.Bool(sensitive || v.Sensitive),
+		}
+		optionList = append(optionList, options)
+	}
+	return optionList
+}
+
 func indexVariables(client *tfe.Client, workspaceID, organization string) (map[string]*tfe.Variable, error) {
 	// List existing variables.
 	variables, err := listVariables(client, workspaceID)
@@ -265,10 +413,10 @@ func indexVariables(client *tfe.Client, workspaceID, organization string) (map[s
 		return nil, fmt.Errorf("cannot list the variables for  %q: %s", organization, err)
 	}
 
-	// Index them by key.
+	// Index them by category and key.
 	indexedVars := map[string]*tfe.Variable{}
 	for _, v := range variables {
-		indexedVars[v.Key] = v
+		indexedVars[tfecli.VariableID(v.Key, v.Category)] = v
 	}
 
 	return indexedVars, nil
@@ -289,7 +437,7 @@ func upsert(client *tfe.Client, workspace *tfe.Workspace, variableID string, opt
 				HCL:       opts.HCL,
 				Sensitive: opts.Sensitive,
 			}
-			log.Debugf("Processing %q: %s [%s]", *(opts.Key), *(opts.Value), variableID)
+			log.Debugf("Processing %q [%s]", *(opts.Key), variableID)
 			if _, err := client.Variables.Update(context.Background(), workspace.ID, variableID, options); err != nil {
 				return fmt.Errorf("cannot update variable %q (%q): %s", *(opts.Key), variableID, err)
 			}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var variableDiffCmd = &cobra.Command{
+	Use:   "diff WORKSPACE [WORKSPACE]",
+	Short: "Compare the variables of a workspace with files or another workspace",
+	Long: `Compare the variables of a workspace with the variables read from files, or with
+the variables of another workspace.
+
+The variables are identified by their key and category, and their values, HCL and
+sensitive flags are compared. The values of the sensitive variables are unknown: they
+are reported as unknown unless their flags differ. A key moving to another category
+is reported as a removal and an addition, not as a change.
+
+The differences are printed as a unified diff, colored on a terminal, or as JSON.
+
+The command exits with a code reflecting the differences:
+  0: the variables are identical, or only the sensitive values are unknown
+  1: tfe-cli could not complete the command
+  2: variables were added, removed or changed`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		format, _ := cmd.Flags().GetString("format")
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		files := variableFiles(cmd)
+		if len(args) == 1 && len(files) == 0 {
+			log.Fatalf("Cannot compare the variables: specify a second workspace or variable files.")
+		}
+		if len(args) == 2 && len(files) > 0 {
+			log.Fatalf("Cannot compare the variables: specify either a second workspace or variable files.")
+		}
+
+		// Read the variables from the files.
+		var fileVars []tfecli.Variable
+		if len(files) > 0 {
+			options, err := readVariableFiles(cmd)
+			if err != nil {
+				log.Fatalf("Cannot read the variable files: %s.", err)
+			}
+			for _, o := range options {
+				fileVars = append(fileVars, tfecli.Variable{Key: *o.Key, Value: *o.Value, Category: *o.Category, HCL: *o.HCL, Sensitive: *o.Sensitive})
+			}
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the variables of the workspaces.
+		workspaceVars := [][]tfecli.Variable{}
+		for _, name := range args {
+			workspace, err := readWorkspace(client, organization, name)
+			if err != nil {
+				log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+			}
+			variables, err := listVariables(client, workspace.ID)
+			if err != nil {
+				log.Fatalf("Cannot list the variables for %q: %s.", name, err)
+			}
+			workspaceVars = append(workspaceVars, workspaceVariables(variables))
+		}
+
+		// Compare them.
+		before, after, newName := workspaceVars[0], fileVars, strings.Join(files, ", ")
+		if len(args) == 2 {
+			after, newName = workspaceVars[1], args[1]
+		}
+		diffs := tfecli.DiffVariables(before, after)
+		color := format == "text" && !noColor && isTerminal(os.Stdout)
+		if err := tfecli.WriteVariableDiff(os.Stdout, diffs, args[0], newName, format, color); err != nil {
+			log.Fatalf("Cannot write the differences: %s.", err)
+		}
+
+		// Exit with the differences, the unknown sensitive values not being known to differ.
+		for _, d := range diffs {
+			if d.Status != tfecli.DiffUnknown {
+				os.Exit(tfecli.ExitChangesPending)
+			}
+		}
+	},
+}
+
+func init() {
+	variableCmd.AddCommand(variableDiffCmd)
+
+	addVariableFileFlags(variableDiffCmd)
+	variableDiffCmd.Flags().String("format", "text", "Specify the output format: text or json")
+	variableDiffCmd.Flags().Bool("no-color", false, "Disable the colors of the text output")
+}
+
+// workspaceVariables converts the variables of a workspace, the values of the sensitive
+// ones being empty.
+func workspaceVariables(variables []*tfe.Variable) []tfecli.Variable {
+	vars := []tfecli.Variable{}
+	for _, v := range variables {
+		vars = append(vars, tfecli.Variable{Key: v.Key, Value: v.Value, Category: v.Category, HCL: v.HCL, Sensitive: v.Sensitive})
+	}
+	return vars
+}
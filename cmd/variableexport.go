@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var variableExportCmd = &cobra.Command{
+	Use:   "export WORKSPACE",
+	Short: "Export the variables of a workspace",
+	Long: `Export the variables of a workspace as tfvars, tfvars-json, dotenv or json.
+
+The values are quoted for the format, and the HCL values are written verbatim, or
+converted to JSON. In the dotenv format, the Terraform variables are prefixed with
+TF_VAR_. The values of the sensitive variables cannot be read: they are written as
+comments in the tfvars and dotenv formats, as null in the json format, and left out
+of the tfvars-json format.
+
+With "--output-dir", the Terraform and environment variables are written to separate
+files, named "terraform" and "env" with the extension of the format. Otherwise, both
+categories are written to stdout in the dotenv and json formats, and only the
+Terraform variables in the tfvars formats.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		format, _ := cmd.Flags().GetString("format")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		extension, ok := tfecli.VariableFormats[format]
+		if !ok {
+			log.Fatalf("Invalid format %q: must be tfvars, tfvars-json, dotenv or json.", format)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the variables.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+		variables, err := listVariables(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot list the variables for %q: %s.", name, err)
+		}
+		vars := workspaceVariables(variables)
+
+		// Warn about the sensitive variables left out.
+		if format == "tfvars-json" {
+			omitted := []string{}
+			for _, v := range vars {
+				if v.Sensitive {
+					omitted = append(omitted, v.Key)
+				}
+			}
+			if len(omitted) > 0 {
+				sort.Strings(omitted)
+				log.Warningf("Sensitive variables left out: %s.", strings.Join(omitted, ", "))
+			}
+		}
+
+		// Write them to stdout.
+		if outputDir == "" {
+			if format == "tfvars" || format == "tfvars-json" {
+				omitted := []string{}
+				for _, v := range vars {
+					if v.Category == tfe.CategoryEnv {
+						omitted = append(omitted, v.Key)
+					}
+				}
+				if len(omitted) > 0 {
+					sort.Strings(omitted)
+					log.Warningf("Environment variables left out: %s.", strings.Join(omitted, ", "))
+				}
+			}
+			if err := tfecli.WriteAllVariables(os.Stdout, vars, format); err != nil {
+				log.Fatalf("Cannot export the variables: %s.", err)
+			}
+			return
+		}
+
+		// Or to a file per category.
+		for _, category := range []tfe.CategoryType{tfe.CategoryTerraform, tfe.CategoryEnv} {
+			categoryVars := []tfecli.Variable{}
+			for _, v := range vars {
+				if v.Category == category {
+					categoryVars = append(categoryVars, v)
+				}
+			}
+			if len(categoryVars) == 0 {
+				continue
+			}
+			b := &bytes.Buffer{}
+			if err := tfecli.WriteVariables(b, categoryVars, format); err != nil {
+				log.Fatalf("Cannot export the variables: %s.", err)
+			}
+			path := filepath.Join(outputDir, string(category)+"."+extension)
+			if err := ioutil.WriteFile(path, b.Bytes(), 0600); err != nil {
+				log.Fatalf("Cannot write the variables: %s.", err)
+			}
+			log.Infof("%d %s variables exported to %q.", len(categoryVars), category, path)
+		}
+	},
+}
+
+func init() {
+	variableCmd.AddCommand(variableExportCmd)
+
+	variableExportCmd.Flags().String("format", "tfvars", "Specify the output format: tfvars, tfvars-json, dotenv or json")
+	variableExportCmd.Flags().StringP("output-dir", "O", "", "Write the Terraform and environment variables to separate files in a directory instead of stdout")
+}
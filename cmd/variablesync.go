@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/rgreinho/tfe-cli/tfecli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var variableSyncCmd = &cobra.Command{
+	Use:   "sync WORKSPACE",
+	Short: "Make the variables of a workspace match files",
+	Long: `Make the variables of a workspace exactly match the variables read from files.
+
+The variables are identified by their key and category. The missing ones are
+created, the different ones are updated, and the ones missing from the files are
+deleted, unless their key matches a "--keep" pattern. Since their values cannot be
+read, the sensitive variables are always updated, so every sync rewrites all the
+secrets and a dry run always shows changes. With "--skip-sensitive-updates", the
+sensitive variables are only updated when their HCL flag changes. They are replaced
+to become non-sensitive.
+
+The plan is printed before being applied, and only printed with "--dry-run". The
+replacements delete the variables before creating them again: they are applied last,
+and only if all the other changes succeeded.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read the flags.
+		name := args[0]
+		keep, _ := cmd.Flags().GetStringArray("keep")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		skipSensitive, _ := cmd.Flags().GetBool("skip-sensitive-updates")
+		parallelism, _ := cmd.Flags().GetInt("parallelism")
+		if parallelism < 1 {
+			log.Fatalf("Invalid parallelism %d: must be at least 1.", parallelism)
+		}
+
+		// Read the variables from the files, refusing to delete all the variables by mistake.
+		if len(variableFiles(cmd)) == 0 {
+			log.Fatalf("Cannot sync the variables: specify at least one variable file.")
+		}
+		desired, err := readVariableFiles(cmd)
+		if err != nil {
+			log.Fatalf("Cannot read the variable files: %s.", err)
+		}
+
+		// Setup the command.
+		organization, client, err := tfecli.Setup(cmd)
+		if err != nil {
+			log.Fatalf("Cannot execute the command: %s.", err)
+		}
+
+		// Retrieve the workspace.
+		workspace, err := readWorkspace(client, organization, name)
+		if err != nil {
+			log.Fatalf("Cannot retrieve workspace %q: %s.", name, err)
+		}
+
+		// Plan the changes.
+		current, err := listVariables(client, workspace.ID)
+		if err != nil {
+			log.Fatalf("Cannot list the variables for %q: %s.", name, err)
+		}
+		changes, err := tfecli.PlanVariableSync(current, desired, keep, skipSensitive)
+		if err != nil {
+			log.Fatalf("Cannot plan the sync: %s.", err)
+		}
+		writeSyncPlan(os.Stdout, changes)
+		if dryRun {
+			return
+		}
+
+		// Apply the changes.
+		errs := tfecli.ApplyVariableSync(&workspaceVariableWriter{client, workspace}, changes, parallelism)
+		for _, err := range errs {
+			log.Errorf("%s.", err)
+		}
+		if len(errs) > 0 {
+			log.Fatalf("Cannot sync the variables of %q: %d errors.", name, len(errs))
+		}
+	},
+}
+
+func init() {
+	variableCmd.AddCommand(variableSyncCmd)
+
+	addVariableFileFlags(variableSyncCmd)
+	variableSyncCmd.Flags().StringArray("keep", []string{}, "Keep the variables missing from the files whose key matches a pattern")
+	variableSyncCmd.Flags().Bool("dry-run", false, "Only print the plan")
+	variableSyncCmd.Flags().Bool("skip-sensitive-updates", false, "Do not update the sensitive variables which stay sensitive")
+	variableSyncCmd.Flags().Int("parallelism", 10, "Specify the maximum number of changes applied at the same time")
+}
+
+// writeSyncPlan writes the changes syncing the variables of a workspace, and their summary.
+func writeSyncPlan(w io.Writer, changes []tfecli.VariableChange) {
+	symbols := map[string]string{
+		tfecli.SyncCreate:  "+",
+		tfecli.SyncUpdate:  "~",
+		tfecli.SyncReplace: "-/+",
+		tfecli.SyncDelete:  "-",
+		tfecli.SyncKeep:    "=",
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", symbols[c.Action], c.Category, c.Key, c.Reason)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nPlan: %s.\n", tfecli.SyncSummary(changes))
+}
+
+// workspaceVariableWriter writes the variables of a workspace.
+type workspaceVariableWriter struct {
+	client    *tfe.Client
+	workspace *tfe.Workspace
+}
+
+func (w *workspaceVariableWriter) Create(options tfe.VariableCreateOptions) error {
+	return upsert(w.client, w.workspace, "", options, false, false)
+}
+
+func (w *workspaceVariableWriter) Update(variableID string, options tfe.VariableCreateOptions) error {
+	return upsert(w.client, w.workspace, variableID, options, true, true)
+}
+
+func (w *workspaceVariableWriter) Delete(variable *tfe.Variable) error {
+	if err := deleteVariable(w.client, w.workspace.ID, variable.ID); err != nil {
+		return err
+	}
+	log.Infof("Variable %q deleted successfully.", variable.Key)
+	return nil
+}
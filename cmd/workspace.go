@@ -35,6 +35,7 @@ var workspaceCreateCmd = &cobra.Command{
 		name := args[0]
 		autoapply, _ := cmd.Flags().GetBool("autoapply")
 		filetriggers, _ := cmd.Flags().GetBool("filetriggers")
+		globalremotestate, _ := cmd.Flags().GetBool("globalremotestate")
 		terraformversion, _ := cmd.Flags().GetString("terraformversion")
 		force, _ := cmd.Flags().GetBool("force")
 		workingdirectory, _ := cmd.Flags().GetString("workingdirectory")
@@ -60,6 +61,10 @@ var workspaceCreateCmd = &cobra.Command{
 					TerraformVersion:    tfe.String(terraformversion),
 					WorkingDirectory:    tfe.String(workingdirectory),
 				}
+				// Keep the remote state sharing unless the flag is set explicitly.
+				if cmd.Flags().Changed("globalremotestate") {
+					options.GlobalRemoteState = tfe.Bool(globalremotestate)
+				}
 				if len(splitVCS) == 3 {
 					options.VCSRepo = &tfe.VCSRepoOptions{
 						Branch:       tfe.String(splitVCS[2]),
@@ -89,6 +94,9 @@ var workspaceCreateCmd = &cobra.Command{
 			TerraformVersion:    tfe.String(terraformversion),
 			WorkingDirectory:    tfe.String(workingdirectory),
 		}
+		if cmd.Flags().Changed("globalremotestate") {
+			options.GlobalRemoteState = tfe.Bool(globalremotestate)
+		}
 		if len(splitVCS) == 3 {
 			options.VCSRepo = &tfe.VCSRepoOptions{
 				Branch:       tfe.String(splitVCS[2]),
@@ -161,6 +169,7 @@ func init() {
 
 	workspaceCreateCmd.Flags().Bool("autoapply", false, "Apply changes automatically")
 	workspaceCreateCmd.Flags().Bool("filetriggers", false, "Filter runs based on the changed files in a VCS push")
+	workspaceCreateCmd.Flags().Bool("globalremotestate", false, "Share the state with all the workspaces of the organization")
 	workspaceCreateCmd.Flags().String("terraformversion", "", "Specify the Terraform version")
 	workspaceCreateCmd.Flags().String("workingdirectory", "", "Specify a relative path that Terraform will execute within")
 	// colon sperated values: <OAuthTokenID>:<repository>:<branch>
@@ -204,7 +213,7 @@ func listWorkspaces(client *tfe.Client, organization string) ([]*tfe.Workspace,
 			ListOptions: tfe.ListOptions{
 				PageNumber: currentPage,
 			}}
-		w, err := client.Workspaces.List(context.Background(), organization, options)
+		w, err := client.Workspaces.List(context.Background(), organization, &options)
 		if err != nil {
 			return nil, err
 		}
@@ -228,3 +237,73 @@ func deleteWorkspace(client *tfe.Client, organization, workspace string) error {
 	}
 	return nil
 }
+
+func listRemoteStateConsumers(client *tfe.Client, workspaceID string) ([]*tfe.Workspace, error) {
+	results := []*tfe.Workspace{}
+	currentPage := 1
+
+	// Go through the pages of results until there is no more pages.
+	for {
+		log.Debugf("Processing page %d.\n", currentPage)
+		options := tfe.RemoteStateConsumersListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: currentPage,
+			}}
+		w, err := client.Workspaces.ListRemoteStateConsumers(context.Background(), workspaceID, &options)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, w.Items...)
+
+		// Check if there is another poage to retrieve.
+		if w.Pagination.NextPage == 0 {
+			break
+		}
+
+		// Incremment the page number.
+		currentPage++
+	}
+
+	return results, nil
+}
+
+// selectWorkspaces retrieves the named workspaces, followed by the workspaces matching the selector if any.
+func selectWorkspaces(client *tfe.Client, organization string, names []string, selector string) ([]*tfe.Workspace, error) {
+	results := []*tfe.Workspace{}
+	selected := map[string]bool{}
+
+	// Retrieve the named workspaces.
+	for _, name := range names {
+		if selected[name] {
+			continue
+		}
+		w, err := readWorkspace(client, organization, name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot retrieve workspace %q: %s", name, err)
+		}
+		results = append(results, w)
+		selected[name] = true
+	}
+
+	if selector == "" {
+		return results, nil
+	}
+
+	// Retrieve the workspaces matching the selector.
+	s, err := tfecli.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := listWorkspaces(client, organization)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the workspaces for %q: %s", organization, err)
+	}
+	for _, w := range workspaces {
+		if !selected[w.Name] && s.Match(w.Name, w.TagNames) {
+			results = append(results, w)
+			selected[w.Name] = true
+		}
+	}
+
+	return results, nil
+}
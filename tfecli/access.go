@@ -0,0 +1,106 @@
+package tfecli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// ParseAccessType validates an access level and converts it to a TFE access type.
+func ParseAccessType(access string) (tfe.AccessType, error) {
+	switch a := tfe.AccessType(access); a {
+	case tfe.AccessRead, tfe.AccessPlan, tfe.AccessWrite, tfe.AccessAdmin, tfe.AccessCustom:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid access %q: must be one of read, plan, write, admin or custom", access)
+}
+
+// ValidatePermission ensures a custom permission value is part of the allowed values.
+func ValidatePermission(name, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s permission %q: must be one of %s", name, value, strings.Join(allowed, ", "))
+}
+
+// AccessMatrix holds the access level of each team for each workspace.
+type AccessMatrix struct {
+	teams      map[string]bool
+	workspaces map[string]map[string]string
+}
+
+// NewAccessMatrix creates an empty access matrix.
+func NewAccessMatrix() *AccessMatrix {
+	return &AccessMatrix{
+		teams:      map[string]bool{},
+		workspaces: map[string]map[string]string{},
+	}
+}
+
+// AddWorkspace registers a workspace, even if no team has access to it.
+func (m *AccessMatrix) AddWorkspace(workspace string) {
+	if _, exists := m.workspaces[workspace]; !exists {
+		m.workspaces[workspace] = map[string]string{}
+	}
+}
+
+// Set records the access level of a team for a workspace.
+func (m *AccessMatrix) Set(workspace, team, access string) {
+	m.AddWorkspace(workspace)
+	m.workspaces[workspace][team] = access
+	m.teams[team] = true
+}
+
+// Get returns the access level of a team for a workspace, or an empty string.
+func (m *AccessMatrix) Get(workspace, team string) string {
+	return m.workspaces[workspace][team]
+}
+
+// Teams returns the sorted list of teams.
+func (m *AccessMatrix) Teams() []string {
+	return sortedKeys(m.teams)
+}
+
+// Workspaces returns the sorted list of workspaces.
+func (m *AccessMatrix) Workspaces() []string {
+	workspaces := []string{}
+	for w := range m.workspaces {
+		workspaces = append(workspaces, w)
+	}
+	sort.Strings(workspaces)
+	return workspaces
+}
+
+// Write renders the matrix as an aligned table, one row per workspace and one column per team.
+func (m *AccessMatrix) Write(w io.Writer) error {
+	teams := m.Teams()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "WORKSPACE\t%s\n", strings.Join(teams, "\t"))
+	for _, workspace := range m.Workspaces() {
+		row := []string{workspace}
+		for _, team := range teams {
+			access := m.Get(workspace, team)
+			if access == "" {
+				access = "-"
+			}
+			row = append(row, access)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,44 @@
+package tfecli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseAccessType(t *testing.T) {
+	testcases := []struct {
+		access  string
+		wantErr bool
+	}{
+		{"read", false},
+		{"custom", false},
+		{"owner", true},
+	}
+	for _, tc := range testcases {
+		_, err := ParseAccessType(tc.access)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %q, got error: %v.", tc.access, err)
+		}
+	}
+}
+
+func TestAccessMatrixWrite(t *testing.T) {
+	matrix := NewAccessMatrix()
+	matrix.Set("network", "ops", "admin")
+	matrix.Set("app", "dev", "write")
+	matrix.Set("app", "ops", "read")
+	matrix.AddWorkspace("sandbox")
+
+	var got bytes.Buffer
+	if err := matrix.Write(&got); err != nil {
+		t.Fatalf("Cannot write the matrix: %s.", err)
+	}
+	want := `WORKSPACE  dev    ops
+app        write  read
+network    -      admin
+sandbox    -      -
+`
+	if got.String() != want {
+		t.Errorf("Incorrect matrix got:\n%s\nwant:\n%s", got.String(), want)
+	}
+}
@@ -3,6 +3,7 @@ package tfecli
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	tfe "github.com/hashicorp/go-tfe"
 	"github.com/spf13/cobra"
@@ -36,17 +37,31 @@ func getToken(cmd *cobra.Command) (string, error) {
 	return token, nil
 }
 
+// Address retrieves the TFE address.
+func Address() string {
+	// Read the environment variable, or use the default address.
+	address := os.Getenv("TFE_ADDRESS")
+	if address == "" {
+		address = tfe.DefaultAddress
+	}
+	return strings.TrimSuffix(address, "/")
+}
+
+// RunURL returns the link to a run in the TFE UI.
+func RunURL(organization, workspace, runID string) string {
+	return fmt.Sprintf("%s/app/%s/workspaces/%s/runs/%s", Address(), organization, workspace, runID)
+}
+
 // NewClient prepares a TFE client.
 func newClient(token string) (*tfe.Client, error) {
 
 	// Read the environment variable as a fallback.
-	Address := os.Getenv("TFE_ADDRESS")
 	BasePath := os.Getenv("TFE_BASEPATH")
 
 	// Prepare TFE config.
 	config := &tfe.Config{
 		Token:    token,
-		Address:  Address,
+		Address:  Address(),
 		BasePath: BasePath,
 	}
 
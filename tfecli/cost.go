@@ -0,0 +1,167 @@
+package tfecli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResourceCost represents the monthly cost estimate of a resource.
+type ResourceCost struct {
+	Address  string `json:"address"`
+	Prior    string `json:"prior-monthly-cost"`
+	Proposed string `json:"proposed-monthly-cost"`
+	Delta    string `json:"delta-monthly-cost"`
+}
+
+// CostEstimateLog represents the details of a cost estimate, as returned in its logs.
+type CostEstimateLog struct {
+	Prior     string `json:"prior-monthly-cost"`
+	Proposed  string `json:"proposed-monthly-cost"`
+	Delta     string `json:"delta-monthly-cost"`
+	Resources struct {
+		Matched   []ResourceCost `json:"matched"`
+		Unmatched []ResourceCost `json:"unmatched"`
+	} `json:"resources"`
+}
+
+// ParseCostEstimateLog parses the logs of a cost estimate.
+func ParseCostEstimateLog(data []byte) (*CostEstimateLog, error) {
+	ce := &CostEstimateLog{}
+	if err := json.Unmarshal(data, ce); err != nil {
+		return nil, fmt.Errorf("invalid cost estimate: %s", err)
+	}
+	sort.Slice(ce.Resources.Matched, func(i, j int) bool {
+		return ce.Resources.Matched[i].Address < ce.Resources.Matched[j].Address
+	})
+	return ce, nil
+}
+
+// WorkspaceCost represents the latest monthly cost estimate of a workspace.
+type WorkspaceCost struct {
+	Workspace string
+	Tags      []string
+	Run       string
+	Prior     float64
+	Proposed  float64
+	Delta     float64
+}
+
+// ParseCost parses a monthly cost, an empty cost being 0.
+func ParseCost(cost string) (float64, error) {
+	if cost == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(cost, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cost %q", cost)
+	}
+	return f, nil
+}
+
+// CostGroup represents the workspaces sharing a tag, with their total monthly costs.
+type CostGroup struct {
+	Tag        string
+	Workspaces []WorkspaceCost
+	Prior      float64
+	Proposed   float64
+	Delta      float64
+}
+
+// UntaggedGroup is the group of the workspaces without any selected tag.
+const UntaggedGroup = "(untagged)"
+
+// GroupCostsByTag groups the workspace costs by tag, sorted by tag name. Only the tags
+// starting with the prefix are used, and a workspace with several of them is counted in
+// each of their groups.
+func GroupCostsByTag(costs []WorkspaceCost, prefix string) []CostGroup {
+	groups := map[string]*CostGroup{}
+	add := func(tag string, c WorkspaceCost) {
+		g, ok := groups[tag]
+		if !ok {
+			g = &CostGroup{Tag: tag}
+			groups[tag] = g
+		}
+		g.Workspaces = append(g.Workspaces, c)
+		g.Prior += c.Prior
+		g.Proposed += c.Proposed
+		g.Delta += c.Delta
+	}
+
+	for _, c := range costs {
+		tagged := false
+		for _, tag := range c.Tags {
+			if strings.HasPrefix(tag, prefix) {
+				add(tag, c)
+				tagged = true
+			}
+		}
+		if !tagged {
+			add(UntaggedGroup, c)
+		}
+	}
+
+	results := []CostGroup{}
+	for _, g := range groups {
+		sort.Slice(g.Workspaces, func(i, j int) bool { return g.Workspaces[i].Workspace < g.Workspaces[j].Workspace })
+		results = append(results, *g)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Tag < results[j].Tag })
+	return results
+}
+
+// WriteCostReport writes the cost groups as a CSV or Markdown table, with a total row per group.
+func WriteCostReport(w io.Writer, groups []CostGroup, format string) error {
+	// The total rows are flagged, since a workspace can be named "total".
+	type costRow struct {
+		cells []string
+		total bool
+	}
+	rows := []costRow{}
+	for _, g := range groups {
+		for _, c := range g.Workspaces {
+			rows = append(rows, costRow{cells: []string{g.Tag, c.Workspace, c.Run, formatAmount(c.Prior), formatAmount(c.Proposed), formatAmount(c.Delta)}})
+		}
+		rows = append(rows, costRow{cells: []string{g.Tag, "total", "", formatAmount(g.Prior), formatAmount(g.Proposed), formatAmount(g.Delta)}, total: true})
+	}
+	header := []string{"tag", "workspace", "run", "prior_monthly_cost", "proposed_monthly_cost", "delta_monthly_cost"}
+
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := cw.Write(r.cells); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "markdown":
+		b := &strings.Builder{}
+		b.WriteString("| Tag | Workspace | Run | Prior | Proposed | Delta |\n")
+		b.WriteString("|---|---|---|---:|---:|---:|\n")
+		for _, row := range rows {
+			r := row.cells
+			if row.total {
+				fmt.Fprintf(b, "| **%s** | **total** | | **$%s** | **$%s** | **%s** |\n", r[0], r[3], r[4], FormatCost(r[5], true))
+				continue
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | $%s | $%s | %s |\n", r[0], r[1], r[2], r[3], r[4], FormatCost(r[5], true))
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("invalid format %q: must be csv or markdown", format)
+}
+
+// formatAmount formats a monthly cost with 2 decimals.
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,85 @@
+package tfecli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCostEstimateLog(t *testing.T) {
+	data := `{
+  "delta-monthly-cost": "12.5",
+  "prior-monthly-cost": "30",
+  "proposed-monthly-cost": "42.5",
+  "resources": {
+    "matched": [
+      {"address": "aws_instance.web", "prior-monthly-cost": "0", "proposed-monthly-cost": "12.5", "delta-monthly-cost": "12.5"},
+      {"address": "aws_db_instance.main", "prior-monthly-cost": "30", "proposed-monthly-cost": "30", "delta-monthly-cost": "0"}
+    ],
+    "unmatched": [{"address": "aws_iam_role.ci"}]
+  }
+}`
+	ce, err := ParseCostEstimateLog([]byte(data))
+	if err != nil {
+		t.Fatalf("Cannot parse the cost estimate: %s.", err)
+	}
+	if ce.Delta != "12.5" || len(ce.Resources.Matched) != 2 || len(ce.Resources.Unmatched) != 1 {
+		t.Fatalf("Incorrect cost estimate got: %+v.", ce)
+	}
+	if got := ce.Resources.Matched[0].Address; got != "aws_db_instance.main" {
+		t.Errorf("Incorrect order of the resources got: %q first, want: %q.", got, "aws_db_instance.main")
+	}
+}
+
+func TestGroupCostsByTag(t *testing.T) {
+	costs := []WorkspaceCost{
+		{Workspace: "app-prod", Tags: []string{"team-app", "prod"}, Prior: 100, Proposed: 120, Delta: 20},
+		{Workspace: "app-dev", Tags: []string{"team-app"}, Prior: 10, Proposed: 5, Delta: -5},
+		{Workspace: "sandbox", Tags: []string{"dev"}, Prior: 1, Proposed: 1},
+	}
+	groups := GroupCostsByTag(costs, "team-")
+	if len(groups) != 2 {
+		t.Fatalf("Incorrect number of groups got: %d, want: 2.", len(groups))
+	}
+	testcases := []struct {
+		group      CostGroup
+		tag        string
+		workspaces int
+		delta      float64
+	}{
+		{groups[0], UntaggedGroup, 1, 0},
+		{groups[1], "team-app", 2, 15},
+	}
+	for _, tc := range testcases {
+		if tc.group.Tag != tc.tag || len(tc.group.Workspaces) != tc.workspaces || tc.group.Delta != tc.delta {
+			t.Errorf("Incorrect group got: %+v, want tag %q with %d workspaces and a delta of %.2f.", tc.group, tc.tag, tc.workspaces, tc.delta)
+		}
+	}
+}
+
+func TestWriteCostReport(t *testing.T) {
+	groups := GroupCostsByTag([]WorkspaceCost{
+		{Workspace: "app-prod", Tags: []string{"prod"}, Run: "run-1", Prior: 100, Proposed: 120, Delta: 20},
+		{Workspace: "total", Tags: []string{"prod"}, Run: "run-2", Prior: 10, Proposed: 10, Delta: 0},
+	}, "")
+	testcases := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "prod,app-prod,run-1,100.00,120.00,20.00\nprod,total,run-2,10.00,10.00,0.00\nprod,total,,110.00,130.00,20.00\n"},
+		{"markdown", "| prod | app-prod | run-1 | $100.00 | $120.00 | +$20.00 |\n"},
+		{"markdown", "| prod | total | run-2 | $10.00 | $10.00 |"},
+		{"markdown", "| **prod** | **total** | | **$110.00** | **$130.00** |"},
+	}
+	for _, tc := range testcases {
+		b := &strings.Builder{}
+		if err := WriteCostReport(b, groups, tc.format); err != nil {
+			t.Fatalf("Cannot write the %s report: %s.", tc.format, err)
+		}
+		if !strings.Contains(b.String(), tc.want) {
+			t.Errorf("Missing %q in the %s report:\n%s", tc.want, tc.format, b.String())
+		}
+	}
+	if err := WriteCostReport(&strings.Builder{}, groups, "xlsx"); err == nil {
+		t.Errorf("An invalid format must be rejected.")
+	}
+}
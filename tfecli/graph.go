@@ -0,0 +1,247 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// EdgeType represents the relationship between two workspaces.
+type EdgeType string
+
+// List all available edge types.
+const (
+	EdgeRunTrigger  EdgeType = "run-trigger"
+	EdgeRemoteState EdgeType = "remote-state"
+)
+
+// Edge represents a dependency between two workspaces: a change in From impacts To.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type EdgeType `json:"type"`
+}
+
+// Node represents a workspace.
+type Node struct {
+	Name              string `json:"name"`
+	GlobalRemoteState bool   `json:"global_remote_state"`
+}
+
+// Graph represents the dependencies between workspaces.
+type Graph struct {
+	nodes map[string]Node
+	edges map[Edge]bool
+}
+
+// NewGraph creates an empty graph.
+func NewGraph() *Graph {
+	return &Graph{
+		nodes: map[string]Node{},
+		edges: map[Edge]bool{},
+	}
+}
+
+// AddNode adds a workspace to the graph.
+func (g *Graph) AddNode(node Node) {
+	g.nodes[node.Name] = node
+}
+
+// AddEdge adds a dependency to the graph, registering its workspaces if needed.
+func (g *Graph) AddEdge(edge Edge) {
+	for _, name := range []string{edge.From, edge.To} {
+		if _, exists := g.nodes[name]; !exists {
+			g.nodes[name] = Node{Name: name}
+		}
+	}
+	g.edges[edge] = true
+}
+
+// Nodes returns the workspaces sorted by name.
+func (g *Graph) Nodes() []Node {
+	nodes := []Node{}
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// Edges returns the dependencies sorted by source, destination and type.
+func (g *Graph) Edges() []Edge {
+	edges := []Edge{}
+	for e := range g.edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Type < edges[j].Type
+	})
+	return edges
+}
+
+// successors returns the sorted list of workspaces directly impacted by a workspace.
+func (g *Graph) successors(name string) []string {
+	seen := map[string]bool{}
+	for e := range g.edges {
+		if e.From == name {
+			seen[e.To] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// Downstream returns the subgraph of the workspaces impacted by a change in a workspace.
+func (g *Graph) Downstream(from string) (*Graph, error) {
+	if _, exists := g.nodes[from]; !exists {
+		return nil, fmt.Errorf("workspace %q not found in the graph", from)
+	}
+
+	// Walk the graph from the workspace.
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.successors(current) {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Keep only the visited workspaces.
+	sub := NewGraph()
+	for name := range visited {
+		sub.AddNode(g.nodes[name])
+	}
+	for e := range g.edges {
+		if visited[e.From] && visited[e.To] {
+			sub.AddEdge(e)
+		}
+	}
+	return sub, nil
+}
+
+// Cycles returns the groups of workspaces depending on each other, using Tarjan's
+// strongly connected components algorithm.
+func (g *Graph) Cycles() [][]string {
+	index := 0
+	indexes := map[string]int{}
+	lowlinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+
+	var connect func(name string)
+	connect = func(name string) {
+		indexes[name] = index
+		lowlinks[name] = index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, next := range g.successors(name) {
+			if next == name {
+				selfLoop = true
+			}
+			if _, visited := indexes[next]; !visited {
+				connect(next)
+				if lowlinks[next] < lowlinks[name] {
+					lowlinks[name] = lowlinks[next]
+				}
+			} else if onStack[next] && indexes[next] < lowlinks[name] {
+				lowlinks[name] = indexes[next]
+			}
+		}
+
+		// Pop the component if the workspace is its root.
+		if lowlinks[name] != indexes[name] {
+			return
+		}
+		component := []string{}
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == name {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range g.Nodes() {
+		if _, visited := indexes[n.Name]; !visited {
+			connect(n.Name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// WriteDOT renders the graph in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph workspaces {\n")
+	for _, n := range g.Nodes() {
+		if n.GlobalRemoteState {
+			fmt.Fprintf(b, "  %q [style=dashed];\n", n.Name)
+			continue
+		}
+		fmt.Fprintf(b, "  %q;\n", n.Name)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Type)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid renders the graph as a Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid identifiers cannot contain all the characters allowed in workspace names.
+	ids := map[string]string{}
+	for i, n := range g.Nodes() {
+		ids[n.Name] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(b, "  %s[\"%s\"]\n", ids[n.Name], n.Name)
+	}
+	for _, e := range g.Edges() {
+		arrow := "-->"
+		if e.Type == EdgeRemoteState {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(b, "  %s %s|%s| %s\n", ids[e.From], arrow, e.Type, ids[e.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON renders the graph and its cycles as JSON.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Nodes  []Node     `json:"nodes"`
+		Edges  []Edge     `json:"edges"`
+		Cycles [][]string `json:"cycles"`
+	}{g.Nodes(), g.Edges(), g.Cycles()})
+}
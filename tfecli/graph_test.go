@@ -0,0 +1,48 @@
+package tfecli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestGraph() *Graph {
+	g := NewGraph()
+	g.AddEdge(Edge{From: "network", To: "app", Type: EdgeRunTrigger})
+	g.AddEdge(Edge{From: "network", To: "dns", Type: EdgeRemoteState})
+	g.AddEdge(Edge{From: "app", To: "monitoring", Type: EdgeRunTrigger})
+	g.AddEdge(Edge{From: "monitoring", To: "app", Type: EdgeRemoteState})
+	g.AddNode(Node{Name: "sandbox"})
+	return g
+}
+
+func TestGraphDownstream(t *testing.T) {
+	testcases := []struct {
+		from string
+		want []string
+	}{
+		{"network", []string{"app", "dns", "monitoring", "network"}},
+		{"app", []string{"app", "monitoring"}},
+		{"dns", []string{"dns"}},
+	}
+	for _, tc := range testcases {
+		sub, err := newTestGraph().Downstream(tc.from)
+		if err != nil {
+			t.Fatalf("Cannot compute the downstream graph of %q: %s.", tc.from, err)
+		}
+		got := []string{}
+		for _, n := range sub.Nodes() {
+			got = append(got, n.Name)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect downstream of %q got: %v, want: %v.", tc.from, got, tc.want)
+		}
+	}
+}
+
+func TestGraphCycles(t *testing.T) {
+	got := newTestGraph().Cycles()
+	want := [][]string{{"app", "monitoring"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect cycles got: %v, want: %v.", got, want)
+	}
+}
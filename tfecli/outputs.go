@@ -0,0 +1,136 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Output represents an output value of a workspace.
+type Output struct {
+	Name      string
+	Value     interface{}
+	Sensitive bool
+}
+
+// OutputFormats lists the formats supported by WriteOutputs.
+var OutputFormats = []string{"json", "dotenv", "export", "tfvars"}
+
+// WriteOutputs writes the outputs in the given format.
+func WriteOutputs(w io.Writer, outputs []Output, format string) error {
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	b := &strings.Builder{}
+
+	switch format {
+	case "json":
+		values := map[string]interface{}{}
+		for _, o := range outputs {
+			values[o.Name] = o.Value
+		}
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	case "dotenv":
+		for _, o := range outputs {
+			fmt.Fprintf(b, "%s=%s\n", EnvName(o.Name), DotenvQuote(OutputString(o.Value)))
+		}
+	case "export":
+		for _, o := range outputs {
+			fmt.Fprintf(b, "export %s=%s\n", EnvName(o.Name), ShellQuote(OutputString(o.Value)))
+		}
+	case "tfvars":
+		for _, o := range outputs {
+			fmt.Fprintf(b, "%s = %s\n", o.Name, HCLLiteral(o.Value))
+		}
+	default:
+		return fmt.Errorf("invalid format %q: must be one of %s", format, strings.Join(OutputFormats, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// OutputString returns the raw representation of a value: strings as is, anything else as JSON.
+func OutputString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// EnvName converts a name to a valid environment variable name.
+func EnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// DotenvQuote quotes a value for a dotenv file.
+func DotenvQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// ShellQuote quotes a value for a POSIX shell.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// HCLString quotes a string as an HCL string literal, escaping the template sequences.
+func HCLString(s string) string {
+	data, _ := json.Marshal(s)
+	quoted := string(data)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	quoted = strings.ReplaceAll(quoted, "%{", "%%{")
+	return quoted
+}
+
+// HCLLiteral converts a value decoded from JSON to an HCL literal expression.
+func HCLLiteral(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return HCLString(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case json.Number:
+		return value.String()
+	case []interface{}:
+		items := []string{}
+		for _, item := range value {
+			items = append(items, HCLLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return "{}"
+		}
+		keys := []string{}
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := []string{}
+		for _, k := range keys {
+			items = append(items, fmt.Sprintf("%s = %s", HCLString(k), HCLLiteral(value[k])))
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return HCLString(fmt.Sprintf("%v", v))
+}
@@ -0,0 +1,47 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHCLLiteral(t *testing.T) {
+	testcases := []struct {
+		json string
+		want string
+	}{
+		{`"a string"`, `"a string"`},
+		{`"${template}"`, `"$${template}"`},
+		{`3.5`, `3.5`},
+		{`100000000`, `100000000`},
+		{`true`, `true`},
+		{`null`, `null`},
+		{`["a", 1]`, `["a", 1]`},
+		{`{"b": {"c": false}, "a": []}`, `{ "a" = [], "b" = { "c" = false } }`},
+	}
+	for _, tc := range testcases {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tc.json), &v); err != nil {
+			t.Fatalf("Invalid test case %q: %s.", tc.json, err)
+		}
+		if got := HCLLiteral(v); got != tc.want {
+			t.Errorf("Incorrect conversion got: %s, want: %s.", got, tc.want)
+		}
+	}
+}
+
+func TestQuotes(t *testing.T) {
+	testcases := []struct {
+		got  string
+		want string
+	}{
+		{DotenvQuote("multi\n\"line\""), `"multi\n\"line\""`},
+		{ShellQuote("it's"), `'it'\''s'`},
+		{EnvName("cluster-endpoint"), "cluster_endpoint"},
+	}
+	for _, tc := range testcases {
+		if tc.got != tc.want {
+			t.Errorf("Incorrect quoting got: %s, want: %s.", tc.got, tc.want)
+		}
+	}
+}
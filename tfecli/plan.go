@@ -0,0 +1,276 @@
+package tfecli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// List all the resource change actions, in the order they are reported.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionReplace = "replace"
+	ActionDelete  = "delete"
+	ActionRead    = "read"
+	ActionNoOp    = "no-op"
+)
+
+// PlanActions lists the actions reported in the plan summaries, no-op excluded.
+var PlanActions = []string{ActionCreate, ActionUpdate, ActionReplace, ActionDelete, ActionRead}
+
+// PlanJSON represents the parts of a JSON execution plan used by tfe-cli.
+type PlanJSON struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	ResourceChanges  []ResourceChange `json:"resource_changes"`
+}
+
+// ResourceChange represents the planned change of a resource instance.
+type ResourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// Action returns the action planned for the resource: create, update, replace, delete, read or no-op.
+func (r ResourceChange) Action() string {
+	actions := r.Change.Actions
+	if len(actions) == 2 {
+		// Both ["delete", "create"] and ["create", "delete"] are replacements.
+		return ActionReplace
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	return ActionNoOp
+}
+
+// ParsePlanJSON parses a JSON execution plan.
+func ParsePlanJSON(data []byte) (*PlanJSON, error) {
+	plan := &PlanJSON{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("invalid JSON plan: %s", err)
+	}
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("invalid JSON plan: missing format version")
+	}
+	return plan, nil
+}
+
+// ChangesByAction groups the addresses of the changed resources by action, no-op excluded.
+func (p *PlanJSON) ChangesByAction() map[string][]string {
+	changes := map[string][]string{}
+	for _, r := range p.ResourceChanges {
+		action := r.Action()
+		if action == ActionNoOp {
+			continue
+		}
+		changes[action] = append(changes[action], r.Address)
+	}
+	return changes
+}
+
+// ValidatePlanActions checks that the actions are reported in the plan summaries.
+func ValidatePlanActions(actions []string) error {
+	for _, action := range actions {
+		valid := false
+		for _, a := range PlanActions {
+			valid = valid || a == action
+		}
+		if !valid {
+			return fmt.Errorf("invalid action %q: must be one of %s", action, strings.Join(PlanActions, ", "))
+		}
+	}
+	return nil
+}
+
+// DisallowedChanges returns the changes whose action is not allowed, no-op being always allowed.
+func (p *PlanJSON) DisallowedChanges(allowed []string) ([]ResourceChange, error) {
+	if err := ValidatePlanActions(allowed); err != nil {
+		return nil, err
+	}
+	isAllowed := map[string]bool{ActionNoOp: true}
+	for _, action := range allowed {
+		isAllowed[action] = true
+	}
+
+	changes := []ResourceChange{}
+	for _, r := range p.ResourceChanges {
+		if !isAllowed[r.Action()] {
+			changes = append(changes, r)
+		}
+	}
+	return changes, nil
+}
+
+// DefaultProtectedTypes lists the resource types holding data, which must not be deleted or replaced by mistake.
+var DefaultProtectedTypes = []string{
+	"aws_db_instance",
+	"aws_dynamodb_table",
+	"aws_efs_file_system",
+	"aws_rds_cluster",
+	"aws_s3_bucket",
+	"azurerm_mssql_database",
+	"azurerm_storage_account",
+	"google_sql_database_instance",
+	"google_storage_bucket",
+}
+
+// ValidateProtectedTypes checks that the protected type patterns use the syntax of path.Match.
+func ValidateProtectedTypes(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected type %q: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ProtectedChanges returns the deletions and replacements of the resources whose type
+// matches one of the patterns. The patterns use the syntax of path.Match, like "aws_db_*",
+// and are validated even if the plan has no deletion.
+func (p *PlanJSON) ProtectedChanges(patterns []string) ([]ResourceChange, error) {
+	if err := ValidateProtectedTypes(patterns); err != nil {
+		return nil, err
+	}
+
+	changes := []ResourceChange{}
+	for _, r := range p.ResourceChanges {
+		action := r.Action()
+		if action != ActionDelete && action != ActionReplace {
+			continue
+		}
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, r.Type); matched {
+				changes = append(changes, r)
+				break
+			}
+		}
+	}
+	return changes, nil
+}
+
+// ReadPatterns reads a list of patterns, one per line, skipping the blank lines and the comments starting with "#".
+func ReadPatterns(r io.Reader) ([]string, error) {
+	patterns := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// PolicyCheckSummary represents the result of a policy check.
+type PolicyCheckSummary struct {
+	Scope          string `json:"scope"`
+	Status         string `json:"status"`
+	Passed         int    `json:"passed"`
+	AdvisoryFailed int    `json:"advisory_failed"`
+	SoftFailed     int    `json:"soft_failed"`
+	HardFailed     int    `json:"hard_failed"`
+}
+
+// CostDelta represents the monthly cost estimate of a run.
+type CostDelta struct {
+	Prior    string `json:"prior_monthly_cost"`
+	Proposed string `json:"proposed_monthly_cost"`
+	Delta    string `json:"delta_monthly_cost"`
+}
+
+// PlanReport summarizes the outcome of a plan.
+type PlanReport struct {
+	Workspace string               `json:"workspace"`
+	Run       string               `json:"run"`
+	URL       string               `json:"url"`
+	Status    string               `json:"status"`
+	Changes   map[string][]string  `json:"changes"`
+	Policies  []PolicyCheckSummary `json:"policies"`
+	Cost      *CostDelta           `json:"cost,omitempty"`
+}
+
+// WriteJSON writes the report as JSON.
+func (r *PlanReport) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteMarkdown writes the report as Markdown, suitable for a pull request comment.
+func (r *PlanReport) WriteMarkdown(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "### Terraform plan for `%s`\n\n", r.Workspace)
+	fmt.Fprintf(b, "Run [%s](%s): **%s**\n\n", r.Run, r.URL, r.Status)
+
+	// Summarize the changes.
+	counts := []string{}
+	for _, action := range PlanActions {
+		counts = append(counts, fmt.Sprintf("%d to %s", len(r.Changes[action]), action))
+	}
+	fmt.Fprintf(b, "#### Resource changes\n\n%s.\n\n", strings.Join(counts, ", "))
+
+	// List the changes.
+	for _, action := range PlanActions {
+		addresses := r.Changes[action]
+		if len(addresses) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "<details><summary>%s%s (%d)</summary>\n\n", strings.ToUpper(action[:1]), action[1:], len(addresses))
+		for _, address := range addresses {
+			fmt.Fprintf(b, "- `%s`\n", address)
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+
+	// List the policy results.
+	if len(r.Policies) > 0 {
+		b.WriteString("#### Policy checks\n\n")
+		b.WriteString("| Scope | Status | Passed | Advisory failed | Soft failed | Hard failed |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, p := range r.Policies {
+			fmt.Fprintf(b, "| %s | %s | %d | %d | %d | %d |\n", p.Scope, p.Status, p.Passed, p.AdvisoryFailed, p.SoftFailed, p.HardFailed)
+		}
+		b.WriteString("\n")
+	}
+
+	// Show the cost estimate.
+	if r.Cost != nil {
+		b.WriteString("#### Cost estimate\n\n")
+		b.WriteString("| Prior | Proposed | Delta |\n")
+		b.WriteString("|---|---|---|\n")
+		fmt.Fprintf(b, "| %s | %s | %s |\n", FormatCost(r.Cost.Prior, false), FormatCost(r.Cost.Proposed, false), FormatCost(r.Cost.Delta, true))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// FormatCost formats a monthly cost in US dollars, with an explicit sign for deltas.
+func FormatCost(cost string, delta bool) string {
+	f, err := strconv.ParseFloat(cost, 64)
+	if err != nil {
+		return "-"
+	}
+	if delta {
+		if f < 0 {
+			return fmt.Sprintf("-$%.2f", -f)
+		}
+		return fmt.Sprintf("+$%.2f", f)
+	}
+	return fmt.Sprintf("$%.2f", f)
+}
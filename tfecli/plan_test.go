@@ -0,0 +1,202 @@
+package tfecli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.1",
+  "terraform_version": "1.3.0",
+  "resource_changes": [
+    {"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["create"]}},
+    {"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "change": {"actions": ["delete"]}},
+    {"address": "aws_db_instance.main", "type": "aws_db_instance", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_role.ci", "type": "aws_iam_role", "change": {"actions": ["update"]}},
+    {"address": "aws_vpc.main", "type": "aws_vpc", "change": {"actions": ["no-op"]}}
+  ]
+}`
+
+func TestParsePlanJSON(t *testing.T) {
+	plan, err := ParsePlanJSON([]byte(testPlanJSON))
+	if err != nil {
+		t.Fatalf("Cannot parse the plan: %s.", err)
+	}
+	want := map[string][]string{
+		ActionCreate:  {"aws_instance.web"},
+		ActionDelete:  {"aws_s3_bucket.logs"},
+		ActionReplace: {"aws_db_instance.main"},
+		ActionUpdate:  {"aws_iam_role.ci"},
+	}
+	if got := plan.ChangesByAction(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect changes got: %v, want: %v.", got, want)
+	}
+
+	if _, err := ParsePlanJSON([]byte(`{"version": 4}`)); err == nil {
+		t.Errorf("A state must not be accepted as a JSON plan.")
+	}
+}
+
+func TestProtectedChanges(t *testing.T) {
+	plan, err := ParsePlanJSON([]byte(testPlanJSON))
+	if err != nil {
+		t.Fatalf("Cannot parse the plan: %s.", err)
+	}
+	testcases := []struct {
+		patterns []string
+		want     []string
+		wantErr  bool
+	}{
+		{DefaultProtectedTypes, []string{"aws_s3_bucket.logs", "aws_db_instance.main"}, false},
+		{[]string{"aws_db_*"}, []string{"aws_db_instance.main"}, false},
+		{[]string{"aws_instance", "aws_iam_role", "aws_vpc"}, []string{}, false},
+		{[]string{"aws_["}, nil, true},
+	}
+	for _, tc := range testcases {
+		changes, err := plan.ProtectedChanges(tc.patterns)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %v, got error: %v.", tc.patterns, err)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		got := []string{}
+		for _, c := range changes {
+			got = append(got, c.Address)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect protected changes for %v got: %v, want: %v.", tc.patterns, got, tc.want)
+		}
+	}
+
+	// The patterns are validated even if nothing is deleted.
+	if _, err := (&PlanJSON{}).ProtectedChanges([]string{"aws_["}); err == nil {
+		t.Errorf("An invalid pattern must be rejected.")
+	}
+}
+
+func TestValidateProtectedTypes(t *testing.T) {
+	testcases := []struct {
+		patterns []string
+		wantErr  bool
+	}{
+		{DefaultProtectedTypes, false},
+		{[]string{"aws_db_*", "google_?ql_*"}, false},
+		{[]string{}, false},
+		{[]string{"aws_s3_bucket", "aws_["}, true},
+	}
+	for _, tc := range testcases {
+		if err := ValidateProtectedTypes(tc.patterns); (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %v, got error: %v.", tc.patterns, err)
+		}
+	}
+}
+
+func TestDisallowedChanges(t *testing.T) {
+	plan, err := ParsePlanJSON([]byte(testPlanJSON))
+	if err != nil {
+		t.Fatalf("Cannot parse the plan: %s.", err)
+	}
+	testcases := []struct {
+		allowed []string
+		want    []string
+		wantErr bool
+	}{
+		{[]string{ActionCreate, ActionUpdate, ActionRead}, []string{"aws_s3_bucket.logs", "aws_db_instance.main"}, false},
+		{[]string{ActionCreate, ActionUpdate, ActionReplace, ActionDelete}, []string{}, false},
+		{[]string{}, []string{"aws_instance.web", "aws_s3_bucket.logs", "aws_db_instance.main", "aws_iam_role.ci"}, false},
+		{[]string{"destroy"}, nil, true},
+	}
+	for _, tc := range testcases {
+		changes, err := plan.DisallowedChanges(tc.allowed)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %v, got error: %v.", tc.allowed, err)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		got := []string{}
+		for _, c := range changes {
+			got = append(got, c.Address)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect disallowed changes for %v got: %v, want: %v.", tc.allowed, got, tc.want)
+		}
+	}
+}
+
+func TestValidatePlanActions(t *testing.T) {
+	testcases := []struct {
+		actions []string
+		wantErr bool
+	}{
+		{PlanActions, false},
+		{[]string{}, false},
+		{[]string{ActionNoOp}, true},
+		{[]string{ActionCreate, "destroy"}, true},
+	}
+	for _, tc := range testcases {
+		if err := ValidatePlanActions(tc.actions); (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %v, got error: %v.", tc.actions, err)
+		}
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	got, err := ReadPatterns(strings.NewReader("# Databases\naws_db_*\n\n  aws_s3_bucket  \n"))
+	if err != nil {
+		t.Fatalf("Cannot read the patterns: %s.", err)
+	}
+	want := []string{"aws_db_*", "aws_s3_bucket"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect patterns got: %v, want: %v.", got, want)
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	testcases := []struct {
+		cost  string
+		delta bool
+		want  string
+	}{
+		{"12.5", false, "$12.50"},
+		{"12.5", true, "+$12.50"},
+		{"-3.2", true, "-$3.20"},
+		{"", false, "-"},
+	}
+	for _, tc := range testcases {
+		if got := FormatCost(tc.cost, tc.delta); got != tc.want {
+			t.Errorf("Incorrect format for %q got: %q, want: %q.", tc.cost, got, tc.want)
+		}
+	}
+}
+
+func TestPlanReportWriteMarkdown(t *testing.T) {
+	report := &PlanReport{
+		Workspace: "my-workspace",
+		Run:       "run-1",
+		URL:       "https://app.terraform.io/app/org/workspaces/my-workspace/runs/run-1",
+		Status:    "planned_and_finished",
+		Changes:   map[string][]string{ActionDelete: {"aws_s3_bucket.logs"}},
+		Policies:  []PolicyCheckSummary{{Scope: "organization", Status: "soft_failed", Passed: 2, SoftFailed: 1}},
+		Cost:      &CostDelta{Prior: "10", Proposed: "7.5", Delta: "-2.5"},
+	}
+	b := &strings.Builder{}
+	if err := report.WriteMarkdown(b); err != nil {
+		t.Fatalf("Cannot write the report: %s.", err)
+	}
+	for _, want := range []string{
+		"0 to create, 0 to update, 0 to replace, 1 to delete, 0 to read.",
+		"<details><summary>Delete (1)</summary>",
+		"- `aws_s3_bucket.logs`",
+		"| organization | soft_failed | 2 | 0 | 1 | 0 |",
+		"| $10.00 | $7.50 | -$2.50 |",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Missing %q in the report:\n%s", want, b.String())
+		}
+	}
+}
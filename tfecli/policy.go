@@ -0,0 +1,174 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// List all the policy results.
+const (
+	PolicyPassed  = "passed"
+	PolicyFailed  = "failed"
+	PolicyErrored = "errored"
+)
+
+// PolicyResult represents the result of a policy in a policy check.
+type PolicyResult struct {
+	PolicySet        string `json:"policy_set"`
+	Policy           string `json:"policy"`
+	EnforcementLevel string `json:"enforcement_level"`
+	Result           string `json:"result"`
+	Error            string `json:"error,omitempty"`
+}
+
+// sentinelResult represents the Sentinel details of a policy check, which are not exposed by go-tfe.
+type sentinelResult struct {
+	Data struct {
+		Attributes struct {
+			Result struct {
+				Sentinel struct {
+					Data map[string]struct {
+						Policies []struct {
+							Policy         string      `json:"policy"`
+							Result         bool        `json:"result"`
+							AllowedFailure bool        `json:"allowed-failure"`
+							Error          interface{} `json:"error"`
+						} `json:"policies"`
+					} `json:"data"`
+				} `json:"sentinel"`
+			} `json:"result"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// ParsePolicyCheck parses the raw JSON:API document of a policy check into the results
+// of its policies, sorted by policy set and policy. The levels map the policy names to
+// their enforcement level; the policies allowed to fail default to advisory.
+func ParsePolicyCheck(data []byte, levels map[string]string) ([]PolicyResult, error) {
+	doc := sentinelResult{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid policy check: %s", err)
+	}
+
+	results := []PolicyResult{}
+	for set, s := range doc.Data.Attributes.Result.Sentinel.Data {
+		for _, p := range s.Policies {
+			// The policies are named "<policy set>/<policy>.sentinel".
+			name := strings.TrimSuffix(p.Policy[strings.LastIndex(p.Policy, "/")+1:], ".sentinel")
+			r := PolicyResult{
+				PolicySet:        set,
+				Policy:           name,
+				EnforcementLevel: levels[name],
+				Result:           PolicyPassed,
+			}
+			if r.EnforcementLevel == "" {
+				r.EnforcementLevel = "unknown"
+				if p.AllowedFailure {
+					r.EnforcementLevel = string(tfe.EnforcementAdvisory)
+				}
+			}
+			switch {
+			case p.Error != nil:
+				r.Result = PolicyErrored
+				r.Error = fmt.Sprintf("%v", p.Error)
+			case !p.Result:
+				r.Result = PolicyFailed
+			}
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].PolicySet != results[j].PolicySet {
+			return results[i].PolicySet < results[j].PolicySet
+		}
+		return results[i].Policy < results[j].Policy
+	})
+	return results, nil
+}
+
+// CheckPolicyOverride reports why a policy check cannot be overridden, if it cannot.
+func CheckPolicyOverride(check *tfe.PolicyCheck) error {
+	if check.Status != tfe.PolicySoftFailed {
+		return fmt.Errorf("policy check %q is %s: only soft failed policy checks can be overridden", check.ID, check.Status)
+	}
+	if check.Actions == nil || !check.Actions.IsOverridable {
+		return fmt.Errorf("policy check %q is not overridable", check.ID)
+	}
+	if check.Permissions == nil || !check.Permissions.CanOverride {
+		return fmt.Errorf("the token is not allowed to override policy check %q", check.ID)
+	}
+	return nil
+}
+
+// junitTestSuites represents a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Name    string           `xml:"name,attr"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// WritePolicyJUnit writes the policy results as a JUnit XML report, with a test suite per
+// policy set. The advisory failures are reported as skipped tests, to not fail the builds.
+func WritePolicyJUnit(w io.Writer, name string, results []PolicyResult) error {
+	report := junitTestSuites{Name: name}
+	index := map[string]int{}
+	for _, r := range results {
+		i, ok := index[r.PolicySet]
+		if !ok {
+			i = len(report.Suites)
+			index[r.PolicySet] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: r.PolicySet})
+		}
+		suite := &report.Suites[i]
+
+		c := junitTestCase{Name: r.Policy, ClassName: r.PolicySet}
+		switch {
+		case r.Result == PolicyErrored:
+			c.Error = &junitMessage{Message: r.Error}
+			suite.Errors++
+		case r.Result == PolicyFailed && r.EnforcementLevel == string(tfe.EnforcementAdvisory):
+			c.Skipped = &junitMessage{Message: "advisory policy failed"}
+			suite.Skipped++
+		case r.Result == PolicyFailed:
+			c.Failure = &junitMessage{Message: fmt.Sprintf("%s policy failed", r.EnforcementLevel), Type: r.EnforcementLevel}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
@@ -0,0 +1,94 @@
+package tfecli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+const testPolicyCheck = `{
+  "data": {
+    "id": "polchk-1",
+    "type": "policy-checks",
+    "attributes": {
+      "result": {
+        "result": false,
+        "passed": 1,
+        "soft-failed": 1,
+        "sentinel": {
+          "schema-version": "1.0.0",
+          "data": {
+            "security": {
+              "can-override": true,
+              "policies": [
+                {"policy": "security/restrict-ingress.sentinel", "result": false, "allowed-failure": false, "error": null},
+                {"policy": "security/require-tags.sentinel", "result": false, "allowed-failure": true, "error": null}
+              ]
+            },
+            "cost": {
+              "policies": [
+                {"policy": "cost/limit-instance-size.sentinel", "result": true, "allowed-failure": false, "error": null}
+              ]
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestParsePolicyCheck(t *testing.T) {
+	got, err := ParsePolicyCheck([]byte(testPolicyCheck), map[string]string{"restrict-ingress": "soft-mandatory"})
+	if err != nil {
+		t.Fatalf("Cannot parse the policy check: %s.", err)
+	}
+	want := []PolicyResult{
+		{PolicySet: "cost", Policy: "limit-instance-size", EnforcementLevel: "unknown", Result: PolicyPassed},
+		{PolicySet: "security", Policy: "require-tags", EnforcementLevel: "advisory", Result: PolicyFailed},
+		{PolicySet: "security", Policy: "restrict-ingress", EnforcementLevel: "soft-mandatory", Result: PolicyFailed},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect results got: %+v, want: %+v.", got, want)
+	}
+}
+
+func TestCheckPolicyOverride(t *testing.T) {
+	testcases := []struct {
+		check   *tfe.PolicyCheck
+		wantErr bool
+	}{
+		{&tfe.PolicyCheck{Status: tfe.PolicySoftFailed, Actions: &tfe.PolicyActions{IsOverridable: true}, Permissions: &tfe.PolicyPermissions{CanOverride: true}}, false},
+		{&tfe.PolicyCheck{Status: tfe.PolicySoftFailed, Actions: &tfe.PolicyActions{IsOverridable: true}, Permissions: &tfe.PolicyPermissions{}}, true},
+		{&tfe.PolicyCheck{Status: tfe.PolicyHardFailed, Actions: &tfe.PolicyActions{}, Permissions: &tfe.PolicyPermissions{CanOverride: true}}, true},
+		{&tfe.PolicyCheck{Status: tfe.PolicyPasses}, true},
+	}
+	for _, tc := range testcases {
+		err := CheckPolicyOverride(tc.check)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of a %s policy check, got error: %v.", tc.check.Status, err)
+		}
+	}
+}
+
+func TestWritePolicyJUnit(t *testing.T) {
+	results := []PolicyResult{
+		{PolicySet: "security", Policy: "require-tags", EnforcementLevel: "advisory", Result: PolicyFailed},
+		{PolicySet: "security", Policy: "restrict-ingress", EnforcementLevel: "soft-mandatory", Result: PolicyFailed},
+		{PolicySet: "security", Policy: "no-public-buckets", EnforcementLevel: "hard-mandatory", Result: PolicyPassed},
+	}
+	b := &strings.Builder{}
+	if err := WritePolicyJUnit(b, "run-1", results); err != nil {
+		t.Fatalf("Cannot write the report: %s.", err)
+	}
+	for _, want := range []string{
+		`<testsuite name="security" tests="3" failures="1" errors="0" skipped="1">`,
+		`<failure message="soft-mandatory policy failed" type="soft-mandatory"></failure>`,
+		`<skipped message="advisory policy failed"></skipped>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Missing %q in the report:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package tfecli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// Exit codes reflecting the status of a run.
+const (
+	// ExitSuccess means the run completed successfully.
+	ExitSuccess = 0
+	// ExitError means tfe-cli could not complete the command.
+	ExitError = 1
+	// ExitChangesPending means the plan has changes which are not applied yet.
+	ExitChangesPending = 2
+	// ExitNoChanges means the plan has no changes.
+	ExitNoChanges = 3
+	// ExitRunFailed means the run errored, or was canceled or discarded.
+	ExitRunFailed = 4
+	// ExitTimeout means the run did not reach the expected status in time.
+	ExitTimeout = 5
+	// ExitProtectedChanges means the plan deletes or replaces protected resources.
+	ExitProtectedChanges = 6
+	// ExitInProgress means the run is still in progress, like planning or applying.
+	ExitInProgress = 7
+)
+
+// IsRunFinal reports whether a run reached a status it cannot leave.
+func IsRunFinal(status tfe.RunStatus) bool {
+	switch status {
+	case tfe.RunApplied, tfe.RunPlannedAndFinished, tfe.RunErrored, tfe.RunDiscarded, tfe.RunCanceled, tfe.RunPolicySoftFailed:
+		return true
+	}
+	return false
+}
+
+// IsRunActionable reports whether a run is waiting for a user action, like a confirmation or a policy override.
+func IsRunActionable(status tfe.RunStatus) bool {
+	switch status {
+	case tfe.RunPlanned, tfe.RunCostEstimated, tfe.RunPolicyChecked, tfe.RunPolicyOverride, tfe.RunPostPlanCompleted:
+		return true
+	}
+	return false
+}
+
+// RunExitCode returns the exit code reflecting the status of a run.
+func RunExitCode(status tfe.RunStatus, hasChanges bool) int {
+	switch {
+	case status == tfe.RunApplied:
+		return ExitSuccess
+	case status == tfe.RunPlannedAndFinished && !hasChanges:
+		return ExitNoChanges
+	case status == tfe.RunPlannedAndFinished, IsRunActionable(status):
+		return ExitChangesPending
+	case IsRunFinal(status):
+		return ExitRunFailed
+	}
+	return ExitInProgress
+}
+
+// AggregateExitCodes combines the exit codes of several runs: the failures of tfe-cli
+// come first, then the failed runs, then the runs in progress and the changes pending.
+// Applied runs and runs without changes are successful.
+func AggregateExitCodes(codes []int) int {
+	for _, want := range []int{ExitError, ExitRunFailed, ExitTimeout, ExitProtectedChanges, ExitInProgress, ExitChangesPending} {
+		for _, code := range codes {
+			if code == want {
+				return want
+			}
+		}
+	}
+	return ExitSuccess
+}
+
+// logFilter removes the control characters from a log stream, keeping the
+// whitespace and the ANSI escape sequences.
+type logFilter struct {
+	r io.Reader
+}
+
+// NewLogFilter returns a reader removing the control characters, like the STX and ETX
+// markers delimiting the TFE logs, from a log stream.
+func NewLogFilter(r io.Reader) io.Reader {
+	return &logFilter{r: r}
+}
+
+func (f *logFilter) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		kept := 0
+		for _, c := range p[:n] {
+			if (c < 0x20 && c != '\n' && c != '\r' && c != '\t' && c != 0x1b) || c == 0x7f {
+				continue
+			}
+			p[kept] = c
+			kept++
+		}
+		// Avoid returning empty reads when a chunk only contains control characters.
+		if kept > 0 || err != nil || n == 0 {
+			return kept, err
+		}
+	}
+}
+
+// Run actions supported by CheckRunAction.
+const (
+	RunActionApply        = "apply"
+	RunActionDiscard      = "discard"
+	RunActionCancel       = "cancel"
+	RunActionForceCancel  = "force-cancel"
+	RunActionForceExecute = "force-execute"
+)
+
+// CheckRunAction ensures an action can be performed on a run, given its status and the
+// permissions of the token.
+func CheckRunAction(run *tfe.Run, action string) error {
+	actions := run.Actions
+	if actions == nil {
+		actions = &tfe.RunActions{}
+	}
+	permissions := run.Permissions
+	if permissions == nil {
+		permissions = &tfe.RunPermissions{}
+	}
+
+	var allowed, permitted bool
+	switch action {
+	case RunActionApply:
+		allowed, permitted = actions.IsConfirmable, permissions.CanApply
+	case RunActionDiscard:
+		allowed, permitted = actions.IsDiscardable, permissions.CanDiscard
+	case RunActionCancel:
+		allowed, permitted = actions.IsCancelable, permissions.CanCancel
+	case RunActionForceCancel:
+		allowed, permitted = actions.IsForceCancelable, permissions.CanForceCancel
+		if !allowed && actions.IsCancelable {
+			if run.ForceCancelAvailableAt.IsZero() {
+				return fmt.Errorf("the run must be canceled before it can be force-canceled")
+			}
+			return fmt.Errorf("the run can only be force-canceled after %s", run.ForceCancelAvailableAt.Format(time.RFC3339))
+		}
+	case RunActionForceExecute:
+		allowed, permitted = run.Status == tfe.RunPending, permissions.CanForceExecute
+		if !allowed {
+			return fmt.Errorf("only pending runs can be force-executed, the run is %s", run.Status)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	if !permitted {
+		return fmt.Errorf("the token is not allowed to %s the run", action)
+	}
+	if !allowed {
+		return fmt.Errorf("the run is %s and cannot be %s", run.Status, pastTense(action))
+	}
+	return nil
+}
+
+func pastTense(action string) string {
+	switch action {
+	case RunActionApply:
+		return "applied"
+	case RunActionCancel:
+		return "canceled"
+	case RunActionForceCancel:
+		return "force-canceled"
+	case RunActionForceExecute:
+		return "force-executed"
+	}
+	return action + "ed"
+}
+
+// PlanSummary summarizes the changes of a plan as `+add ~change -destroy`.
+func PlanSummary(plan *tfe.Plan) string {
+	if plan == nil || plan.Status != tfe.PlanFinished {
+		return "-"
+	}
+	return fmt.Sprintf("+%d ~%d -%d", plan.ResourceAdditions, plan.ResourceChanges, plan.ResourceDestructions)
+}
+
+// PlanDuration returns how long the plan of a run took, or 0 if it did not complete.
+func PlanDuration(run *tfe.Run) time.Duration {
+	ts := run.StatusTimestamps
+	if ts == nil || ts.PlanningAt.IsZero() {
+		return 0
+	}
+	for _, end := range []time.Time{ts.PlannedAt, ts.PlannedAndFinishedAt} {
+		if !end.IsZero() {
+			return end.Sub(ts.PlanningAt)
+		}
+	}
+	if ts.ApplyingAt.IsZero() && !ts.ErroredAt.IsZero() {
+		return ts.ErroredAt.Sub(ts.PlanningAt)
+	}
+	return 0
+}
+
+// ApplyDuration returns how long the apply of a run took, or 0 if it did not complete.
+func ApplyDuration(run *tfe.Run) time.Duration {
+	ts := run.StatusTimestamps
+	if ts == nil || ts.ApplyingAt.IsZero() {
+		return 0
+	}
+	for _, end := range []time.Time{ts.AppliedAt, ts.ErroredAt} {
+		if !end.IsZero() {
+			return end.Sub(ts.ApplyingAt)
+		}
+	}
+	return 0
+}
+
+// FormatDuration formats a duration rounded to the second, or `-` for a zero duration.
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// ParseSince parses a point in time given as a RFC3339 date, a YYYY-MM-DD date, or a
+// duration before now, like `36h` or `7d`.
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", since, now.Location()); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(since, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(since, "d"))
+		if err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: must be a date or a duration like 36h or 7d", since)
+}
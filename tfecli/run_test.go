@@ -0,0 +1,108 @@
+package tfecli
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestRunExitCode(t *testing.T) {
+	testcases := []struct {
+		status     tfe.RunStatus
+		hasChanges bool
+		want       int
+	}{
+		{tfe.RunApplied, true, ExitSuccess},
+		{tfe.RunPlannedAndFinished, false, ExitNoChanges},
+		{tfe.RunPlannedAndFinished, true, ExitChangesPending},
+		{tfe.RunPlanned, true, ExitChangesPending},
+		{tfe.RunPolicyOverride, true, ExitChangesPending},
+		{tfe.RunErrored, false, ExitRunFailed},
+		{tfe.RunDiscarded, true, ExitRunFailed},
+		{tfe.RunPlanning, false, ExitInProgress},
+		{tfe.RunApplying, true, ExitInProgress},
+	}
+	for _, tc := range testcases {
+		if got := RunExitCode(tc.status, tc.hasChanges); got != tc.want {
+			t.Errorf("Incorrect exit code for %q got: %d, want: %d.", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestAggregateExitCodes(t *testing.T) {
+	testcases := []struct {
+		codes []int
+		want  int
+	}{
+		{[]int{ExitSuccess, ExitNoChanges}, ExitSuccess},
+		{[]int{ExitSuccess, ExitChangesPending, ExitNoChanges}, ExitChangesPending},
+		{[]int{ExitChangesPending, ExitRunFailed}, ExitRunFailed},
+		{[]int{ExitChangesPending, ExitInProgress, ExitSuccess}, ExitInProgress},
+		{[]int{ExitRunFailed, ExitError, ExitSuccess}, ExitError},
+		{[]int{}, ExitSuccess},
+	}
+	for _, tc := range testcases {
+		if got := AggregateExitCodes(tc.codes); got != tc.want {
+			t.Errorf("Incorrect exit code for %v got: %d, want: %d.", tc.codes, got, tc.want)
+		}
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	got, err := ioutil.ReadAll(NewLogFilter(strings.NewReader("\x02Terraform v1.3.0\n\x1b[1mPlan:\x1b[0m 1 to add.\x00\x03")))
+	if err != nil {
+		t.Fatalf("Cannot read the logs: %s.", err)
+	}
+	want := "Terraform v1.3.0\n\x1b[1mPlan:\x1b[0m 1 to add."
+	if string(got) != want {
+		t.Errorf("Incorrect filtering got: %q, want: %q.", got, want)
+	}
+}
+
+func TestCheckRunAction(t *testing.T) {
+	testcases := []struct {
+		run     *tfe.Run
+		action  string
+		wantErr bool
+	}{
+		{&tfe.Run{Status: tfe.RunPlanned, Actions: &tfe.RunActions{IsConfirmable: true}, Permissions: &tfe.RunPermissions{CanApply: true}}, RunActionApply, false},
+		{&tfe.Run{Status: tfe.RunPlanned, Actions: &tfe.RunActions{IsConfirmable: true}, Permissions: &tfe.RunPermissions{}}, RunActionApply, true},
+		{&tfe.Run{Status: tfe.RunApplied, Actions: &tfe.RunActions{}, Permissions: &tfe.RunPermissions{CanDiscard: true}}, RunActionDiscard, true},
+		{&tfe.Run{Status: tfe.RunPending, Permissions: &tfe.RunPermissions{CanForceExecute: true}}, RunActionForceExecute, false},
+		{&tfe.Run{Status: tfe.RunPlanning, Actions: &tfe.RunActions{IsCancelable: true}, Permissions: &tfe.RunPermissions{CanForceCancel: true}}, RunActionForceCancel, true},
+	}
+	for _, tc := range testcases {
+		err := CheckRunAction(tc.run, tc.action)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect validation of %q on a %s run, got error: %v.", tc.action, tc.run.Status, err)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		since string
+		want  time.Time
+	}{
+		{"36h", time.Date(2022, 6, 14, 0, 0, 0, 0, time.UTC)},
+		{"7d", time.Date(2022, 6, 8, 12, 0, 0, 0, time.UTC)},
+		{"2022-06-01", time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"2022-06-01T08:00:00Z", time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testcases {
+		got, err := ParseSince(tc.since, now)
+		if err != nil {
+			t.Fatalf("Cannot parse %q: %s.", tc.since, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("Incorrect parsing of %q got: %s, want: %s.", tc.since, got, tc.want)
+		}
+	}
+	if _, err := ParseSince("last week", now); err == nil {
+		t.Errorf("Expected an error when parsing %q.", "last week")
+	}
+}
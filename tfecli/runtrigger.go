@@ -0,0 +1,48 @@
+package tfecli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxRunTriggerSources is the maximum number of source workspaces a workspace can have.
+const MaxRunTriggerSources = 20
+
+// ParseWorkspaceRef parses a workspace reference of the form `[organization/]workspace`
+// and ensures it belongs to the given organization.
+func ParseWorkspaceRef(ref, organization string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	if len(parts) > 2 {
+		return "", fmt.Errorf("invalid workspace %q: must be of the form [organization/]workspace", ref)
+	}
+	if parts[0] != organization {
+		return "", fmt.Errorf("workspace %q must belong to organization %q", ref, organization)
+	}
+	return parts[1], nil
+}
+
+// CheckRunTriggerSources ensures a workspace does not exceed the maximum number of source
+// workspaces. The sources to add are counted once, and only if they do not exist yet.
+func CheckRunTriggerSources(workspace string, existing, added []string) error {
+	sources := map[string]bool{}
+	for _, s := range existing {
+		sources[s] = true
+	}
+	count := 0
+	for _, s := range added {
+		if !sources[s] {
+			sources[s] = true
+			count++
+		}
+	}
+	if len(sources) > MaxRunTriggerSources {
+		return fmt.Errorf(
+			"workspace %q cannot have more than %d source workspaces (%d existing, %d to add)",
+			workspace, MaxRunTriggerSources, len(sources)-count, count,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package tfecli
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseWorkspaceRef(t *testing.T) {
+	testcases := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"network", "network", false},
+		{"acme/network", "network", false},
+		{"other/network", "", true},
+		{"acme/team/network", "", true},
+		{"team/network/acme", "", true},
+	}
+	for _, tc := range testcases {
+		got, err := ParseWorkspaceRef(tc.ref, "acme")
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect parsing of %q, got error: %v.", tc.ref, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Incorrect workspace for %q got: %q, want: %q.", tc.ref, got, tc.want)
+		}
+	}
+}
+
+func TestCheckRunTriggerSources(t *testing.T) {
+	sources := func(prefix string, n int) []string {
+		names := []string{}
+		for i := 0; i < n; i++ {
+			names = append(names, fmt.Sprintf("%s-%d", prefix, i))
+		}
+		return names
+	}
+	testcases := []struct {
+		existing []string
+		added    []string
+		wantErr  bool
+	}{
+		{sources("ws", 0), sources("new", 1), false},
+		{sources("ws", 19), sources("new", 1), false},
+		{sources("ws", 20), sources("new", 0), false},
+		{sources("ws", 20), sources("new", 1), true},
+		{sources("ws", 15), sources("new", 10), true},
+		{sources("ws", 19), []string{"new", "new", "new"}, false},
+		{sources("ws", 20), sources("ws", 5), false},
+	}
+	for _, tc := range testcases {
+		err := CheckRunTriggerSources("app", tc.existing, tc.added)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect check of %d existing and %v added sources, got error: %v.", len(tc.existing), tc.added, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tfecli
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Selector selects workspaces by name and tags.
+type Selector struct {
+	names       []string
+	tags        []string
+	excludeTags []string
+}
+
+// ParseSelector parses a comma separated list of `name=GLOB`, `tag=TAG` and `!tag=TAG` terms.
+// A workspace is selected when it matches all the terms, and an empty selector is an error.
+func ParseSelector(selector string) (*Selector, error) {
+	s := &Selector{}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		kv := strings.SplitN(term, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("invalid selector term %q: the format must be key=value", term)
+		}
+		switch kv[0] {
+		case "name":
+			if _, err := path.Match(kv[1], ""); err != nil {
+				return nil, fmt.Errorf("invalid name pattern %q: %s", kv[1], err)
+			}
+			s.names = append(s.names, kv[1])
+		case "tag":
+			s.tags = append(s.tags, kv[1])
+		case "!tag":
+			s.excludeTags = append(s.excludeTags, kv[1])
+		default:
+			return nil, fmt.Errorf("invalid selector term %q: the key must be one of name, tag or !tag", term)
+		}
+	}
+	if len(s.names)+len(s.tags)+len(s.excludeTags) == 0 {
+		return nil, fmt.Errorf("invalid selector %q: it must have at least one term", selector)
+	}
+	return s, nil
+}
+
+// Match reports whether a workspace is selected.
+func (s *Selector) Match(name string, tags []string) bool {
+	for _, pattern := range s.names {
+		if matched, _ := path.Match(pattern, name); !matched {
+			return false
+		}
+	}
+	hasTag := map[string]bool{}
+	for _, t := range tags {
+		hasTag[t] = true
+	}
+	for _, t := range s.tags {
+		if !hasTag[t] {
+			return false
+		}
+	}
+	for _, t := range s.excludeTags {
+		if hasTag[t] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package tfecli
+
+import (
+	"testing"
+)
+
+func TestSelectorMatch(t *testing.T) {
+	testcases := []struct {
+		selector string
+		name     string
+		tags     []string
+		want     bool
+	}{
+		{"name=app-*", "app-frontend", nil, true},
+		{"name=app-*", "network", nil, false},
+		{"tag=prod", "network", []string{"prod", "eu"}, true},
+		{"name=app-*,tag=prod", "app-backend", []string{"staging"}, false},
+		{"tag=prod,!tag=frozen", "app-backend", []string{"prod", "frozen"}, false},
+	}
+	for _, tc := range testcases {
+		s, err := ParseSelector(tc.selector)
+		if err != nil {
+			t.Fatalf("Cannot parse selector %q: %s.", tc.selector, err)
+		}
+		if got := s.Match(tc.name, tc.tags); got != tc.want {
+			t.Errorf("Incorrect match of %q with %q got: %t, want: %t.", tc.name, tc.selector, got, tc.want)
+		}
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	for _, selector := range []string{"prod", "owner=me", "name=[", "tag=", "", " ", ","} {
+		if _, err := ParseSelector(selector); err == nil {
+			t.Errorf("Expected an error when parsing %q.", selector)
+		}
+	}
+}
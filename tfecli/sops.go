@@ -0,0 +1,627 @@
+package tfecli
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// treeItem is a key and its value in a tree read from a YAML, JSON or dotenv file. The
+// values are []treeItem for the maps, []interface{} for the lists, or scalars.
+type treeItem struct {
+	Key   string
+	Value interface{}
+}
+
+// parseYAMLTree parses a YAML document whose root is a map, keeping the order of the keys.
+func parseYAMLTree(data []byte, filename string) ([]treeItem, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	if len(doc.Content) == 0 {
+		return []treeItem{}, nil
+	}
+	v, err := yamlValue(doc.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	tree, ok := v.([]treeItem)
+	if !ok {
+		return nil, fmt.Errorf("%s: the content must be a YAML map", filename)
+	}
+	return tree, nil
+}
+
+// yamlValue converts a YAML node to a tree value.
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		items := []treeItem{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: the keys must be strings", key.Line)
+			}
+			value, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, treeItem{Key: key.Value, Value: value})
+		}
+		return items, nil
+	case yaml.SequenceNode:
+		values := []interface{}{}
+		for _, n := range node.Content {
+			value, err := yamlValue(n)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, fmt.Errorf("line %d: %s", node.Line, err)
+	}
+	return value, nil
+}
+
+// parseJSONTree parses a JSON object, keeping the order of the keys.
+func parseJSONTree(data []byte, filename string) ([]treeItem, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := jsonValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	tree, ok := v.([]treeItem)
+	if !ok {
+		return nil, fmt.Errorf("%s: the content must be a JSON object", filename)
+	}
+	return tree, nil
+}
+
+// jsonValue decodes the next JSON value to a tree value.
+func jsonValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		items := []treeItem{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := jsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, treeItem{Key: key.(string), Value: value})
+		}
+		_, err := dec.Token()
+		return items, err
+	case json.Delim('['):
+		values := []interface{}{}
+		for dec.More() {
+			value, err := jsonValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		_, err := dec.Token()
+		return values, err
+	}
+	return t, nil
+}
+
+// treeVariable converts a top-level item of a tree to a variable.
+func treeVariable(item treeItem) (Variable, error) {
+	raw, err := json.Marshal(plainValue(item.Value))
+	if err != nil {
+		return Variable{}, fmt.Errorf("invalid value for %q: %s", item.Key, err)
+	}
+	return jsonVariable(item.Key, raw)
+}
+
+// plainValue converts a tree value to a value which can be encoded as JSON.
+func plainValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []treeItem:
+		m := map[string]interface{}{}
+		for _, item := range v {
+			m[item.Key] = plainValue(item.Value)
+		}
+		return m
+	case []interface{}:
+		values := []interface{}{}
+		for _, value := range v {
+			values = append(values, plainValue(value))
+		}
+		return values
+	case []byte:
+		return string(v)
+	}
+	return v
+}
+
+// sopsMetadata represents the metadata of a file encrypted with SOPS. Only the age
+// keys are supported.
+type sopsMetadata struct {
+	AgeKeys           []string
+	LastModified      string
+	MAC               string
+	MACOnlyEncrypted  bool
+	UnencryptedSuffix string
+	EncryptedSuffix   string
+	UnencryptedRegex  *regexp.Regexp
+	EncryptedRegex    *regexp.Regexp
+}
+
+// List the formats of the files encrypted with SOPS.
+const (
+	sopsYAML   = "yaml"
+	sopsJSON   = "json"
+	sopsDotenv = "dotenv"
+	sopsBinary = "binary"
+)
+
+// sopsFormat detects the format of a file encrypted with SOPS, and returns an empty
+// string if the file is not encrypted. Like SOPS, the files without a YAML, JSON or
+// dotenv extension, such as the tfvars files, are encrypted as binary files.
+func sopsFormat(data []byte, filename string) string {
+	switch {
+	case strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml"):
+		if tree, err := parseYAMLTree(data, filename); err == nil && hasTreeKey(tree, "sops") {
+			return sopsYAML
+		}
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		if tree, err := parseJSONTree(data, filename); err == nil && hasTreeKey(tree, "sops") {
+			if !strings.HasSuffix(filename, ".json") && len(tree) == 2 && hasTreeKey(tree, "data") {
+				return sopsBinary
+			}
+			return sopsJSON
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "sops_version=") {
+				return sopsDotenv
+			}
+		}
+	}
+	return ""
+}
+
+// hasTreeKey reports whether a tree has a top-level key.
+func hasTreeKey(tree []treeItem, key string) bool {
+	for _, item := range tree {
+		if item.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSOPSFile reports whether the content of a file is encrypted with SOPS.
+func IsSOPSFile(data []byte, filename string) bool {
+	return sopsFormat(data, filename) != ""
+}
+
+// ReadAgeIdentities reads the age identities from files.
+func ReadAgeIdentities(files []string) ([]age.Identity, error) {
+	identities := []age.Identity{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the age identities: %s", err)
+		}
+		ids, err := age.ParseIdentities(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the age identities from %q: %s", file, err)
+		}
+		identities = append(identities, ids...)
+	}
+	return identities, nil
+}
+
+// DecryptSOPSVars decrypts a file encrypted with SOPS and returns its variables. The
+// top-level keys of the YAML, JSON and dotenv files are the variables, while the
+// decrypted content of the binary files is parsed with the parse function. The
+// decrypted variables are sensitive, but the ones left unencrypted by the rules of the
+// file, such as its unencrypted suffix, are not.
+func DecryptSOPSVars(data []byte, filename string, identities []age.Identity, parse func([]byte, string) ([]Variable, error)) ([]Variable, error) {
+	// Read the tree and the metadata.
+	var tree []treeItem
+	var metadata *sopsMetadata
+	var err error
+	format := sopsFormat(data, filename)
+	switch format {
+	case sopsYAML:
+		tree, err = parseYAMLTree(data, filename)
+	case sopsJSON, sopsBinary:
+		tree, err = parseJSONTree(data, filename)
+	case sopsDotenv:
+		tree, metadata, err = parseSOPSDotenv(data, filename)
+	default:
+		return nil, fmt.Errorf("%s: the file is not encrypted with SOPS", filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		values := []treeItem{}
+		for _, item := range tree {
+			if item.Key != "sops" {
+				values = append(values, item)
+				continue
+			}
+			if metadata, err = readSOPSMetadata(item.Value); err != nil {
+				return nil, fmt.Errorf("%s: %s", filename, err)
+			}
+		}
+		tree = values
+	}
+
+	// Decrypt the values.
+	d := &sopsDecrypter{metadata: metadata, hash: sha512.New()}
+	if d.key, err = d.dataKey(identities); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	encrypted := make([]bool, len(tree))
+	for i := range tree {
+		if tree[i].Value, encrypted[i], err = d.decrypt(tree[i].Value, []string{tree[i].Key}); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	}
+	if err := d.verifyMAC(); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+
+	// Convert the values.
+	if format == sopsBinary {
+		plaintext, _ := plainValue(tree[0].Value).(string)
+		variables, err := parse([]byte(plaintext), filename)
+		if err != nil {
+			return nil, err
+		}
+		for i := range variables {
+			variables[i].Sensitive = true
+		}
+		return variables, nil
+	}
+	variables := []Variable{}
+	for i, item := range tree {
+		v, err := treeVariable(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+		v.Sensitive = encrypted[i]
+		variables = append(variables, v)
+	}
+	return variables, nil
+}
+
+// readSOPSMetadata reads the metadata of a YAML or JSON file encrypted with SOPS.
+func readSOPSMetadata(v interface{}) (*sopsMetadata, error) {
+	items, ok := v.([]treeItem)
+	if !ok {
+		return nil, fmt.Errorf("invalid SOPS metadata")
+	}
+	fields := map[string]string{}
+	for _, item := range items {
+		if item.Key != "age" {
+			fields[item.Key] = fmt.Sprint(item.Value)
+			continue
+		}
+		keys, _ := item.Value.([]interface{})
+		for i, key := range keys {
+			recipient, _ := key.([]treeItem)
+			for _, field := range recipient {
+				fields[fmt.Sprintf("age.%d.%s", i, field.Key)] = fmt.Sprint(field.Value)
+			}
+		}
+	}
+	return newSOPSMetadata(fields)
+}
+
+// sopsDotenvAgeRe matches the flattened age keys of a dotenv file encrypted with SOPS.
+var sopsDotenvAgeRe = regexp.MustCompile(`^sops_age__list_(\d+)__map_(\w+)$`)
+
+// parseSOPSDotenv parses a dotenv file encrypted with SOPS. Unlike the regular dotenv
+// files, the values are not quoted and their new lines are escaped.
+func parseSOPSDotenv(data []byte, filename string) ([]treeItem, *sopsMetadata, error) {
+	tree := []treeItem{}
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("%s:%d: invalid line: the format must be KEY=value", filename, line)
+		}
+		key, value := parts[0], strings.Replace(parts[1], `\n`, "\n", -1)
+		switch {
+		case sopsDotenvAgeRe.MatchString(key):
+			m := sopsDotenvAgeRe.FindStringSubmatch(key)
+			fields[fmt.Sprintf("age.%s.%s", m[1], m[2])] = value
+		case strings.HasPrefix(key, "sops_"):
+			fields[strings.TrimPrefix(key, "sops_")] = value
+		default:
+			tree = append(tree, treeItem{Key: key, Value: value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	metadata, err := newSOPSMetadata(fields)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return tree, metadata, nil
+}
+
+// newSOPSMetadata creates the metadata from its fields, the age keys being named
+// "age.INDEX.FIELD".
+func newSOPSMetadata(fields map[string]string) (*sopsMetadata, error) {
+	m := &sopsMetadata{
+		LastModified:      fields["lastmodified"],
+		MAC:               fields["mac"],
+		MACOnlyEncrypted:  fields["mac_only_encrypted"] == "true",
+		UnencryptedSuffix: fields["unencrypted_suffix"],
+		EncryptedSuffix:   fields["encrypted_suffix"],
+	}
+	for i := 0; ; i++ {
+		enc, ok := fields[fmt.Sprintf("age.%d.enc", i)]
+		if !ok {
+			break
+		}
+		m.AgeKeys = append(m.AgeKeys, enc)
+	}
+	if len(m.AgeKeys) == 0 {
+		return nil, fmt.Errorf("the file is not encrypted with age, the only supported key type")
+	}
+	for _, r := range []struct {
+		field string
+		re    **regexp.Regexp
+	}{
+		{"unencrypted_regex", &m.UnencryptedRegex},
+		{"encrypted_regex", &m.EncryptedRegex},
+	} {
+		if fields[r.field] == "" {
+			continue
+		}
+		re, err := regexp.Compile(fields[r.field])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", r.field, err)
+		}
+		*r.re = re
+	}
+	return m, nil
+}
+
+// sopsDecrypter decrypts the values of a file encrypted with SOPS, and computes their MAC.
+type sopsDecrypter struct {
+	metadata *sopsMetadata
+	key      []byte
+	hash     hash.Hash
+}
+
+// dataKey decrypts the data key of the file with one of the age identities.
+func (d *sopsDecrypter) dataKey(identities []age.Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("cannot decrypt the data key: no age identity")
+	}
+	var err error
+	for _, enc := range d.metadata.AgeKeys {
+		var r io.Reader
+		if r, err = age.Decrypt(armor.NewReader(strings.NewReader(enc)), identities...); err != nil {
+			continue
+		}
+		return ioutil.ReadAll(r)
+	}
+	return nil, fmt.Errorf("cannot decrypt the data key: %s", err)
+}
+
+// encrypted reports whether the values at a path are encrypted, according to the rules of the file.
+func (d *sopsDecrypter) encrypted(path []string) bool {
+	m := d.metadata
+	matches := func(f func(string) bool) bool {
+		for _, p := range path {
+			if f(p) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case m.UnencryptedSuffix != "":
+		return !matches(func(p string) bool { return strings.HasSuffix(p, m.UnencryptedSuffix) })
+	case m.EncryptedSuffix != "":
+		return matches(func(p string) bool { return strings.HasSuffix(p, m.EncryptedSuffix) })
+	case m.UnencryptedRegex != nil:
+		return !matches(m.UnencryptedRegex.MatchString)
+	case m.EncryptedRegex != nil:
+		return matches(m.EncryptedRegex.MatchString)
+	}
+	return true
+}
+
+// decrypt decrypts a tree value and adds its leaves to the MAC, in the order of the
+// file. It also reports whether any leaf was encrypted.
+func (d *sopsDecrypter) decrypt(v interface{}, path []string) (interface{}, bool, error) {
+	switch v := v.(type) {
+	case []treeItem:
+		encrypted := false
+		for i := range v {
+			value, e, err := d.decrypt(v[i].Value, append(path[:len(path):len(path)], v[i].Key))
+			if err != nil {
+				return nil, false, err
+			}
+			v[i].Value = value
+			encrypted = encrypted || e
+		}
+		return v, encrypted, nil
+	case []interface{}:
+		// The items of a list share the path of the list, and the comments of a list are
+		// encrypted as items, which are removed.
+		encrypted := false
+		items := []interface{}{}
+		for i := range v {
+			value, e, err := d.decrypt(v[i], path)
+			if err != nil {
+				return nil, false, err
+			}
+			if _, ok := value.(sopsComment); !ok {
+				items = append(items, value)
+			}
+			encrypted = encrypted || e
+		}
+		return items, encrypted, nil
+	}
+
+	encrypted := d.encrypted(path)
+	value := v
+	if encrypted {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false, fmt.Errorf("the value of %q is not encrypted", strings.Join(path, "."))
+		}
+		var err error
+		if value, err = d.decryptValue(s, strings.Join(path, ":")+":"); err != nil {
+			return nil, false, fmt.Errorf("cannot decrypt the value of %q: %s", strings.Join(path, "."), err)
+		}
+	}
+	// Like the other comments, which are not part of the tree, they are not in the MAC.
+	if _, ok := value.(sopsComment); !ok && (encrypted || !d.metadata.MACOnlyEncrypted) {
+		d.hash.Write(sopsBytes(value))
+	}
+	return value, encrypted, nil
+}
+
+// sopsComment represents a comment encrypted as a value.
+type sopsComment string
+
+// sopsValueRe matches a value encrypted by SOPS.
+var sopsValueRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// decryptValue decrypts a value with the data key, and converts it to its type.
+func (d *sopsDecrypter) decryptValue(value, additionalData string) (interface{}, error) {
+	if value == "" {
+		return "", nil
+	}
+	m := sopsValueRe.FindStringSubmatch(value)
+	if m == nil {
+		return nil, fmt.Errorf("invalid encrypted value")
+	}
+	parts := [][]byte{}
+	for _, s := range m[1:4] {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted value: %s", err)
+		}
+		parts = append(parts, b)
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(d.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed")
+	}
+
+	switch m[4] {
+	case "str":
+		return string(plaintext), nil
+	case "bytes":
+		return plaintext, nil
+	case "int":
+		return strconv.Atoi(string(plaintext))
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	case "comment":
+		return sopsComment(plaintext), nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", m[4])
+}
+
+// verifyMAC verifies the MAC of the file against the MAC of the decrypted values.
+func (d *sopsDecrypter) verifyMAC() error {
+	if d.metadata.MAC == "" {
+		return fmt.Errorf("the file has no MAC")
+	}
+	mac, err := d.decryptValue(d.metadata.MAC, d.metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt the MAC: %s", err)
+	}
+	if fmt.Sprintf("%X", d.hash.Sum(nil)) != mac {
+		return fmt.Errorf("the MAC does not match: the file was modified")
+	}
+	return nil
+}
+
+// sopsBytes converts a value to bytes like SOPS does to compute the MAC.
+func sopsBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	case int:
+		return []byte(strconv.Itoa(v))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+		return []byte(v)
+	case bool:
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	case nil:
+		return []byte{}
+	}
+	return []byte(fmt.Sprint(v))
+}
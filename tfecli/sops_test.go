@@ -0,0 +1,267 @@
+package tfecli
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// sopsFixture encrypts test files like SOPS does.
+type sopsFixture struct {
+	t        *testing.T
+	identity *age.X25519Identity
+	key      []byte
+	mac      []string
+}
+
+func newSOPSFixture(t *testing.T) *sopsFixture {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Cannot generate an age identity: %s.", err)
+	}
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &sopsFixture{t: t, identity: identity, key: key}
+}
+
+// enc encrypts a value at a path, and adds it to the MAC.
+func (f *sopsFixture) enc(plaintext, typ string, path ...string) string {
+	f.mac = append(f.mac, plaintext)
+	return f.encrypt(plaintext, typ, strings.Join(path, ":")+":")
+}
+
+// plain adds an unencrypted value to the MAC.
+func (f *sopsFixture) plain(plaintext string) string {
+	f.mac = append(f.mac, plaintext)
+	return plaintext
+}
+
+func (f *sopsFixture) encrypt(plaintext, typ, additionalData string) string {
+	block, _ := aes.NewCipher(f.key)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, 32)
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+	b64 := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", b64(data), b64(iv), b64(tag), typ)
+}
+
+// dataKey returns the data key encrypted for the age identity.
+func (f *sopsFixture) dataKey() string {
+	b := &bytes.Buffer{}
+	a := armor.NewWriter(b)
+	w, err := age.Encrypt(a, f.identity.Recipient())
+	if err != nil {
+		f.t.Fatalf("Cannot encrypt the data key: %s.", err)
+	}
+	w.Write(f.key)
+	w.Close()
+	a.Close()
+	return b.String()
+}
+
+// macValue returns the encrypted MAC of the values.
+func (f *sopsFixture) macValue() string {
+	h := sha512.New()
+	for _, v := range f.mac {
+		h.Write([]byte(v))
+	}
+	return f.encrypt(fmt.Sprintf("%X", h.Sum(nil)), "str", "2023-01-01T00:00:00Z")
+}
+
+func TestDecryptSOPSVars(t *testing.T) {
+	f := newSOPSFixture(t)
+	dbPassword := f.enc("secret", "str", "db_password")
+	team := f.enc("ops", "str", "tags", "team")
+	cidr0 := f.enc("10.0.0.0/16", "str", "tags", "cidrs")
+	cidr1 := f.enc("10.1.0.0/16", "str", "tags", "cidrs")
+	// The comments are encrypted, but are not part of the MAC.
+	comment := f.encrypt(" Database.", "comment", "")
+	cidrComment := f.encrypt(" Secondary.", "comment", "tags:cidrs:")
+	port := f.enc("5432", "int", "port")
+	region := f.plain("us-east-1")
+	dataKey := "            " + strings.ReplaceAll(strings.TrimSpace(f.dataKey()), "\n", "\n            ")
+	yamlFile := fmt.Sprintf(`#%s
+db_password: %s
+tags:
+    team: %s
+    cidrs:
+        - %s
+        - %s
+        - %s
+port: %s
+region_unencrypted: %s
+sops:
+    age:
+        - recipient: %s
+          enc: |
+%s
+    lastmodified: "2023-01-01T00:00:00Z"
+    mac: %s
+    unencrypted_suffix: _unencrypted
+    version: 3.7.3
+`, comment, dbPassword, team, cidr0, cidrComment, cidr1, port, region, f.identity.Recipient(), dataKey, f.macValue())
+
+	got, err := DecryptSOPSVars([]byte(yamlFile), "secrets.yaml", []age.Identity{f.identity}, ParseVars)
+	if err != nil {
+		t.Fatalf("Cannot decrypt the file: %s.", err)
+	}
+	want := []Variable{
+		{Key: "db_password", Value: "secret", Sensitive: true},
+		{Key: "tags", Value: "{\n  cidrs = [\"10.0.0.0/16\", \"10.1.0.0/16\"]\n  team  = \"ops\"\n}", HCL: true, Sensitive: true},
+		{Key: "port", Value: "5432", Sensitive: true},
+		{Key: "region_unencrypted", Value: "us-east-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect decryption got: %+v, want: %+v.", got, want)
+	}
+
+	// Tampering with a value or using another identity must fail.
+	other, _ := age.GenerateX25519Identity()
+	tampered := strings.Replace(yamlFile, "region_unencrypted: us-east-1", "region_unencrypted: us-west-2", 1)
+	testcases := []struct {
+		data       string
+		identities []age.Identity
+		want       string
+	}{
+		{tampered, []age.Identity{f.identity}, "the MAC does not match"},
+		{yamlFile, []age.Identity{other}, "cannot decrypt the data key"},
+		{yamlFile, nil, "no age identity"},
+	}
+	for _, tc := range testcases {
+		_, err := DecryptSOPSVars([]byte(tc.data), "secrets.yaml", tc.identities, ParseVars)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Incorrect error got: %v, want: %q.", err, tc.want)
+		}
+	}
+}
+
+func TestDecryptSOPSFormats(t *testing.T) {
+	// JSON.
+	f := newSOPSFixture(t)
+	jsonFile := fmt.Sprintf(`{"token": %q, "enabled": %q, "sops": {"age": [{"recipient": %q, "enc": %q}], "lastmodified": "2023-01-01T00:00:00Z", "mac": %%q, "unencrypted_suffix": "_unencrypted", "version": "3.7.3"}}`,
+		f.enc("abc", "str", "token"), f.enc("True", "bool", "enabled"), f.identity.Recipient(), f.dataKey())
+	jsonFile = fmt.Sprintf(jsonFile, f.macValue())
+	checkSOPSVars(t, f, jsonFile, "secrets.tfvars.json", []Variable{
+		{Key: "token", Value: "abc", Sensitive: true},
+		{Key: "enabled", Value: "true", Sensitive: true},
+	})
+
+	// Dotenv.
+	f = newSOPSFixture(t)
+	dotenvFile := fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s\n#ENC[comment]\nAWS_REGION_unencrypted=%s\nsops_age__list_0__map_enc=%s\nsops_age__list_0__map_recipient=%s\nsops_lastmodified=2023-01-01T00:00:00Z\nsops_unencrypted_suffix=_unencrypted\nsops_version=3.7.3\n",
+		f.enc("key", "str", "AWS_SECRET_ACCESS_KEY"), f.plain("us-east-1"), strings.ReplaceAll(f.dataKey(), "\n", `\n`), f.identity.Recipient())
+	dotenvFile += "sops_mac=" + f.macValue() + "\n"
+	checkSOPSVars(t, f, dotenvFile, "secrets.env", []Variable{
+		{Key: "AWS_SECRET_ACCESS_KEY", Value: "key", Sensitive: true},
+		{Key: "AWS_REGION_unencrypted", Value: "us-east-1"},
+	})
+
+	// Binary, such as a tfvars file.
+	f = newSOPSFixture(t)
+	binaryFile := fmt.Sprintf(`{"data": %q, "sops": {"age": [{"recipient": %q, "enc": %q}], "lastmodified": "2023-01-01T00:00:00Z", "mac": %%q, "unencrypted_suffix": "_unencrypted", "version": "3.7.3"}}`,
+		f.enc("region = \"us-east-1\"\nzones = [\"a\"]\n", "str", "data"), f.identity.Recipient(), f.dataKey())
+	binaryFile = fmt.Sprintf(binaryFile, f.macValue())
+	checkSOPSVars(t, f, binaryFile, "prod.tfvars", []Variable{
+		{Key: "region", Value: "us-east-1", Sensitive: true},
+		{Key: "zones", Value: `["a"]`, HCL: true, Sensitive: true},
+	})
+
+	for _, plain := range []struct{ data, filename string }{
+		{"region = \"us-east-1\"\n", "prod.tfvars"},
+		{`{"region": "us-east-1"}`, "prod.tfvars.json"},
+		{"region: us-east-1\n", "prod.yaml"},
+		{"REGION=us-east-1\n", ".env"},
+	} {
+		if IsSOPSFile([]byte(plain.data), plain.filename) {
+			t.Errorf("The file %q must not be detected as encrypted.", plain.filename)
+		}
+	}
+}
+
+func checkSOPSVars(t *testing.T, f *sopsFixture, data, filename string, want []Variable) {
+	if !IsSOPSFile([]byte(data), filename) {
+		t.Errorf("The file %q must be detected as encrypted.", filename)
+	}
+	got, err := DecryptSOPSVars([]byte(data), filename, []age.Identity{f.identity}, ParseVarData)
+	if err != nil {
+		t.Fatalf("Cannot decrypt %q: %s.", filename, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect decryption of %q got: %+v, want: %+v.", filename, got, want)
+	}
+}
+
+// TestDecryptSOPSGoldenFiles decrypts files encrypted by the sops binary (3.13.3) for the
+// age key of testdata/sops/key.txt, the tfvars file being encrypted as a binary file.
+func TestDecryptSOPSGoldenFiles(t *testing.T) {
+	identities, err := ReadAgeIdentities([]string{filepath.Join("testdata", "sops", "key.txt")})
+	if err != nil {
+		t.Fatalf("Cannot read the test age key: %s.", err)
+	}
+	zones := Variable{Key: "zones", Value: `["a", "b"]`, HCL: true, Sensitive: true}
+	tags := Variable{Key: "tags", Value: "{\n  team = \"ops\"\n}", HCL: true, Sensitive: true}
+	testcases := []struct {
+		filename string
+		parse    func([]byte, string) ([]Variable, error)
+		want     []Variable
+	}{
+		// The comments, inline comments and comments in lists are encrypted.
+		{"prod.yaml", ParseVarData, []Variable{
+			{Key: "region", Value: "us-east-1", Sensitive: true},
+			{Key: "db_password", Value: "s3cr3t", Sensitive: true},
+			{Key: "replicas", Value: "3", Sensitive: true},
+			{Key: "debug", Value: "false", Sensitive: true},
+			zones,
+			tags,
+		}},
+		{"prod.tfvars.json", ParseVarData, []Variable{
+			{Key: "region", Value: "us-east-1", Sensitive: true},
+			{Key: "db_password", Value: "s3cr3t", Sensitive: true},
+			{Key: "replicas", Value: "3", Sensitive: true},
+			{Key: "ratio", Value: "1.5", Sensitive: true},
+			{Key: "debug", Value: "false", Sensitive: true},
+			zones,
+			tags,
+		}},
+		{"prod.env", ParseDotenv, []Variable{
+			{Key: "AWS_REGION", Value: "us-east-1", Sensitive: true},
+			{Key: "AWS_SECRET_ACCESS_KEY", Value: "s3cr3t", Sensitive: true},
+		}},
+		{"prod.tfvars", ParseVarData, []Variable{
+			{Key: "region", Value: "us-east-1", Sensitive: true},
+			{Key: "db_password", Value: "s3cr3t", Sensitive: true},
+			zones,
+		}},
+	}
+	for _, tc := range testcases {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "sops", tc.filename))
+		if err != nil {
+			t.Fatalf("Cannot read %q: %s.", tc.filename, err)
+		}
+		if !IsSOPSFile(data, tc.filename) {
+			t.Errorf("The file %q must be detected as encrypted.", tc.filename)
+		}
+		got, err := DecryptSOPSVars(data, tc.filename, identities, tc.parse)
+		if err != nil {
+			t.Errorf("Cannot decrypt %q: %s.", tc.filename, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect decryption of %q got: %+v, want: %+v.", tc.filename, got, tc.want)
+		}
+	}
+}
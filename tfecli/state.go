@@ -0,0 +1,134 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// State represents the parts of a Terraform state file used by tfe-cli.
+type State struct {
+	Version          int                    `json:"version"`
+	TerraformVersion string                 `json:"terraform_version"`
+	Serial           int64                  `json:"serial"`
+	Lineage          string                 `json:"lineage"`
+	Outputs          map[string]StateOutput `json:"outputs"`
+	Resources        []StateResource        `json:"resources"`
+}
+
+// StateOutput represents an output value stored in a Terraform state file.
+type StateOutput struct {
+	Value     json.RawMessage `json:"value"`
+	Type      json.RawMessage `json:"type"`
+	Sensitive bool            `json:"sensitive"`
+}
+
+// StateResource represents a resource stored in a Terraform state file.
+type StateResource struct {
+	Module    string            `json:"module"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	Instances []json.RawMessage `json:"instances"`
+}
+
+// Address returns the address of the resource, as used by the Terraform CLI.
+func (r StateResource) Address() string {
+	parts := []string{}
+	if r.Module != "" {
+		parts = append(parts, r.Module)
+	}
+	if r.Mode == "data" {
+		parts = append(parts, "data")
+	}
+	parts = append(parts, r.Type, r.Name)
+	return strings.Join(parts, ".")
+}
+
+// providerRe extracts the provider source address from `provider["source"].alias`.
+var providerRe = regexp.MustCompile(`provider\["([^"]+)"\]`)
+
+// ProviderSource returns the source address of the provider managing the resource.
+func (r StateResource) ProviderSource() string {
+	if m := providerRe.FindStringSubmatch(r.Provider); m != nil {
+		return m[1]
+	}
+	return r.Provider
+}
+
+// ParseState parses the content of a Terraform state file.
+func ParseState(data []byte) (*State, error) {
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid state: %s", err)
+	}
+	if state.Version < 4 {
+		return nil, fmt.Errorf("unsupported state format version %d", state.Version)
+	}
+	return state, nil
+}
+
+// WriteSummary writes a human readable summary of the providers and resources of the state.
+func (s *State) WriteSummary(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Terraform version: %s\n", s.TerraformVersion)
+	fmt.Fprintf(b, "Serial: %d\n", s.Serial)
+	fmt.Fprintf(b, "Lineage: %s\n", s.Lineage)
+
+	// Count the resources managed by each provider.
+	providers := map[string]int{}
+	for _, r := range s.Resources {
+		providers[r.ProviderSource()]++
+	}
+	names := []string{}
+	for p := range providers {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(b, "\nProviders (%d):\n", len(names))
+	for _, p := range names {
+		fmt.Fprintf(b, "  %s: %d resources\n", p, providers[p])
+	}
+
+	// List the resources.
+	fmt.Fprintf(b, "\nResources (%d):\n", len(s.Resources))
+	for _, r := range s.Resources {
+		if len(r.Instances) > 1 {
+			fmt.Fprintf(b, "  %s (%d instances)\n", r.Address(), len(r.Instances))
+			continue
+		}
+		fmt.Fprintf(b, "  %s\n", r.Address())
+	}
+
+	// List the outputs.
+	outputs := []string{}
+	for o := range s.Outputs {
+		outputs = append(outputs, o)
+	}
+	sort.Strings(outputs)
+	fmt.Fprintf(b, "\nOutputs (%d):\n", len(outputs))
+	for _, o := range outputs {
+		if s.Outputs[o].Sensitive {
+			fmt.Fprintf(b, "  %s (sensitive)\n", o)
+			continue
+		}
+		fmt.Fprintf(b, "  %s\n", o)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// SetStateSerial replaces the serial of a raw Terraform state, leaving the other fields untouched.
+func SetStateSerial(data []byte, serial int64) ([]byte, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid state: %s", err)
+	}
+	raw["serial"] = json.RawMessage(fmt.Sprintf("%d", serial))
+	return json.MarshalIndent(raw, "", "  ")
+}
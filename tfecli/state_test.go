@@ -0,0 +1,64 @@
+package tfecli
+
+import (
+	"testing"
+)
+
+const testState = `{
+  "version": 4,
+  "terraform_version": "1.3.0",
+  "serial": 7,
+  "lineage": "3f9c-lineage",
+  "outputs": {},
+  "resources": [
+    {
+      "module": "module.vpc",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "this",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"].east",
+      "instances": [{}]
+    },
+    {
+      "mode": "data",
+      "type": "aws_ami",
+      "name": "ubuntu",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [{}]
+    }
+  ]
+}`
+
+func TestParseState(t *testing.T) {
+	state, err := ParseState([]byte(testState))
+	if err != nil {
+		t.Fatalf("Cannot parse the state: %s.", err)
+	}
+	testcases := []struct {
+		got  string
+		want string
+	}{
+		{state.Resources[0].Address(), "module.vpc.aws_vpc.this"},
+		{state.Resources[1].Address(), "data.aws_ami.ubuntu"},
+		{state.Resources[0].ProviderSource(), "registry.terraform.io/hashicorp/aws"},
+	}
+	for _, tc := range testcases {
+		if tc.got != tc.want {
+			t.Errorf("Incorrect parsing got: %s, want: %s.", tc.got, tc.want)
+		}
+	}
+}
+
+func TestSetStateSerial(t *testing.T) {
+	raw, err := SetStateSerial([]byte(testState), 12)
+	if err != nil {
+		t.Fatalf("Cannot set the serial: %s.", err)
+	}
+	state, err := ParseState(raw)
+	if err != nil {
+		t.Fatalf("Cannot parse the state: %s.", err)
+	}
+	if state.Serial != 12 || state.Lineage != "3f9c-lineage" || len(state.Resources) != 2 {
+		t.Errorf("Incorrect state after setting the serial: %+v.", state)
+	}
+}
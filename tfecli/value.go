@@ -0,0 +1,90 @@
+package tfecli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ValueResolver reads the values of the variables from their source:
+//   - "@path": the content of a file,
+//   - "-": the standard input, which can only be read once,
+//   - "env:NAME": an environment variable,
+//   - "cmd:COMMAND": the output of a shell command, like "cmd:pass show db".
+//
+// The other values are used as is. A backslash right before a source escapes it, like
+// "\-" or "\env:prod", and is removed, while the other backslashes are kept. The
+// trailing newline of the values read from a source is removed.
+type ValueResolver struct {
+	Stdin     io.Reader
+	LookupEnv func(string) (string, bool)
+	Command   func(string) ([]byte, error)
+
+	stdinRead bool
+}
+
+// NewValueResolver creates a resolver reading from the standard input, the environment and a POSIX shell.
+func NewValueResolver() *ValueResolver {
+	return &ValueResolver{
+		Stdin:     os.Stdin,
+		LookupEnv: os.LookupEnv,
+		Command: func(command string) ([]byte, error) {
+			c := exec.Command("sh", "-c", command)
+			c.Stderr = os.Stderr
+			return c.Output()
+		},
+	}
+}
+
+// escapedSourceRe matches the values escaping a source with backslashes, like "\\env:prod".
+var escapedSourceRe = regexp.MustCompile(`^\\+(@|-|env:|cmd:)`)
+
+// Resolve returns the value of a variable from its source. The errors never contain the value.
+func (r *ValueResolver) Resolve(value string) (string, error) {
+	switch {
+	case escapedSourceRe.MatchString(value):
+		return value[1:], nil
+	case strings.HasPrefix(value, "@"):
+		data, err := ioutil.ReadFile(value[1:])
+		if err != nil {
+			return "", fmt.Errorf("cannot read the file %q: %s", value[1:], err)
+		}
+		return trimNewline(data), nil
+	case value == "-":
+		if r.stdinRead {
+			return "", fmt.Errorf("the standard input can only be used by one variable")
+		}
+		r.stdinRead = true
+		data, err := ioutil.ReadAll(r.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("cannot read the standard input: %s", err)
+		}
+		return trimNewline(data), nil
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		v, ok := r.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("the environment variable %q is not set", name)
+		}
+		return trimNewline([]byte(v)), nil
+	case strings.HasPrefix(value, "cmd:"):
+		data, err := r.Command(strings.TrimPrefix(value, "cmd:"))
+		if err != nil {
+			return "", fmt.Errorf("the command failed: %s", err)
+		}
+		return trimNewline(data), nil
+	}
+	return value, nil
+}
+
+// trimNewline removes a trailing newline.
+func trimNewline(data []byte) string {
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	return string(data)
+}
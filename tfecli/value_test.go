@@ -0,0 +1,65 @@
+package tfecli
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValueResolver(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(path, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatalf("Cannot write the file: %s.", err)
+	}
+	r := &ValueResolver{
+		Stdin: strings.NewReader("from stdin\r\n"),
+		LookupEnv: func(name string) (string, bool) {
+			if name == "DB_PASSWORD" {
+				return "from env\n", true
+			}
+			return "", false
+		},
+		Command: func(command string) ([]byte, error) {
+			if command == "pass show db" {
+				return []byte("from command\n"), nil
+			}
+			return nil, errors.New("exit status 1")
+		},
+	}
+
+	testcases := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "plain", false},
+		{"@" + path, "s3cr3t", false},
+		{`\@handle`, "@handle", false},
+		{`\-`, "-", false},
+		{`\env:prod`, "env:prod", false},
+		{`\cmd:ls`, "cmd:ls", false},
+		{`\\@handle`, `\@handle`, false},
+		{`\d+`, `\d+`, false},
+		{`\\server\share`, `\\server\share`, false},
+		{"-", "from stdin", false},
+		{"-", "", true},
+		{"env:DB_PASSWORD", "from env", false},
+		{"env:MISSING", "", true},
+		{"cmd:pass show db", "from command", false},
+		{"cmd:false", "", true},
+		{"@" + filepath.Join(dir, "missing"), "", true},
+	}
+	for _, tc := range testcases {
+		got, err := r.Resolve(tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Incorrect resolution of %q, got error: %v.", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Incorrect resolution of %q got: %q, want: %q.", tc.value, got, tc.want)
+		}
+	}
+}
@@ -1,43 +1,517 @@
 package tfecli
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"path"
 	"regexp"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
-// HCLVariable defines a HCLVariable.
-type HCLVariable struct {
-	key   string
-	value string
+// Variable represents a variable read from a file. An empty category means the
+// category of the file is used.
+type Variable struct {
+	Key       string
+	Value     string
+	Category  tfe.CategoryType
+	HCL       bool
+	Sensitive bool
 }
 
-func (h HCLVariable) String() string {
-	return fmt.Sprintf("%s=%s", h.key, h.value)
+func (v Variable) String() string {
+	return fmt.Sprintf("%s=%s", v.Key, v.Value)
 }
 
-// ParseVarFile reads an HCL varfile and returns its content as a list of HCL variables.
-func ParseVarFile(varFile string) ([]HCLVariable, error) {
+// ParseVarFile reads an HCL varfile, or a JSON or YAML one depending on its extension, and returns its variables.
+func ParseVarFile(varFile string) ([]Variable, error) {
 	fileContent, err := ioutil.ReadFile(varFile)
 	if err != nil {
-		return []HCLVariable{}, fmt.Errorf("cannot read the file %q: %s", varFile, err)
+		return []Variable{}, fmt.Errorf("cannot read the file %q: %s", varFile, err)
+	}
+
+	return ParseVarData(fileContent, varFile)
+}
+
+// ParseVarData parses the content of a varfile as JSON if its name ends with ".json", as
+// YAML if it ends with ".yaml" or ".yml", and as HCL otherwise.
+func ParseVarData(data []byte, filename string) ([]Variable, error) {
+	switch {
+	case strings.HasSuffix(filename, ".json"):
+		return ParseJSONVars(data, filename)
+	case strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml"):
+		return ParseYAMLVars(data, filename)
+	}
+	return ParseVars(data, filename)
+}
+
+// ParseEnvFile reads a dotenv file and returns its variables.
+func ParseEnvFile(envFile string) ([]Variable, error) {
+	fileContent, err := ioutil.ReadFile(envFile)
+	if err != nil {
+		return []Variable{}, fmt.Errorf("cannot read the file %q: %s", envFile, err)
+	}
+
+	return ParseDotenv(fileContent, envFile)
+}
+
+// ParseVars parses the content of an HCL varfile and returns its variables, in the order
+// of the file. Strings, numbers and booleans become regular variables, while the other
+// values are kept as canonical HCL for HCL variables. Only literal values are allowed.
+func ParseVars(data []byte, filename string) ([]Variable, error) {
+	file, diags := hclsyntax.ParseConfig(data, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diagnosticsError(diags)
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diagnosticsError(diags)
+	}
+
+	// Keep the order of the file.
+	sorted := []*hcl.Attribute{}
+	for _, attr := range attrs {
+		sorted = append(sorted, attr)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte })
+
+	annotations, err := parseAnnotations(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	variables := []Variable{}
+	for _, attr := range sorted {
+		// Evaluating without context rejects the references and the function calls.
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diagnosticsError(diags)
+		}
+		v, err := ctyVariable(attr.Name, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", attr.Range.Filename, attr.Range.Start.Line, err)
+		}
+		if v, err = ApplyVariableFlags(v, annotations.take(attr.Range.Start.Line)); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", attr.Range.Filename, attr.Range.Start.Line, err)
+		}
+		variables = append(variables, v)
+	}
+	if err := annotations.check(filename); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+// ParseJSONVars parses the content of a JSON varfile and returns its variables, in the
+// order of the file. Strings, numbers and booleans become regular variables, while the
+// objects and the lists become HCL variables.
+func ParseJSONVars(data []byte, filename string) ([]Variable, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("%s: the content must be a JSON object", filename)
+	}
+
+	variables := []Variable{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+		key := t.(string)
+		raw := json.RawMessage{}
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%s: invalid value for %q: %s", filename, key, err)
+		}
+		v, err := jsonVariable(key, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+		variables = append(variables, v)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	return variables, nil
+}
+
+// ParseYAMLVars parses the content of a YAML varfile and returns its variables, in the
+// order of the file, like ParseJSONVars.
+func ParseYAMLVars(data []byte, filename string) ([]Variable, error) {
+	tree, err := parseYAMLTree(data, filename)
+	if err != nil {
+		return nil, err
 	}
+	variables := []Variable{}
+	for _, item := range tree {
+		v, err := treeVariable(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+		variables = append(variables, v)
+	}
+	return variables, nil
+}
+
+// dotenvKeyRe matches the valid keys of a dotenv file.
+var dotenvKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseDotenv parses the content of a dotenv file and returns its variables, in the order
+// of the file. The lines may start with "export", the comments start with "#", and the
+// values may be unquoted, single quoted without escapes, or double quoted with the \n,
+// \r, \t, \", \\ and \$ escapes. The quoted values may span several lines.
+func ParseDotenv(data []byte, filename string) ([]Variable, error) {
+	annotations, err := parseAnnotations(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	variables := []Variable{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// Split the key and the value.
+		text = strings.TrimPrefix(text, "export ")
+		parts := strings.SplitN(text, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !dotenvKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: invalid line: the format must be KEY=value", filename, line)
+		}
+		value := strings.TrimLeft(parts[1], " \t")
+
+		// Parse the value.
+		start := line
+		switch {
+		case strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'"):
+			quote := value[:1]
+			raw := value[1:]
+
+			// Read the next lines until the closing quote.
+			end := closingQuote(raw, quote)
+			for end < 0 && scanner.Scan() {
+				line++
+				raw += "\n" + scanner.Text()
+				end = closingQuote(raw, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated quoted value for %q", filename, start, key)
+			}
+			rest := strings.TrimSpace(raw[end+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("%s:%d: unexpected characters after the quoted value of %q", filename, line, key)
+			}
+			value = raw[:end]
+			if quote == `"` {
+				value = dotenvUnescape(value)
+			}
+		default:
+			// Remove the inline comments.
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
+		}
 
-	return SimpleParseVarFile(string(fileContent)), nil
+		v, err := ApplyVariableFlags(Variable{Key: key, Value: value}, annotations.take(start))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, start, err)
+		}
+		variables = append(variables, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	if err := annotations.check(filename); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+// List all the variable flags, used by the annotations and the mapping files.
+const (
+	FlagSensitive = "sensitive"
+	FlagHCL       = "hcl"
+	FlagEnv       = "env"
+	FlagTerraform = "terraform"
+)
+
+// ApplyVariableFlags marks a variable as sensitive, HCL, or as an environment or a
+// Terraform variable. A regular value marked as HCL is quoted as an HCL string, unless
+// it is a number or a boolean literal, which is kept as is.
+func ApplyVariableFlags(v Variable, flags []string) (Variable, error) {
+	for _, flag := range flags {
+		switch flag {
+		case FlagSensitive:
+			v.Sensitive = true
+		case FlagHCL:
+			if !v.HCL {
+				if !isHCLScalar(v.Value) {
+					v.Value = HCLString(v.Value)
+				}
+				v.HCL = true
+			}
+		case FlagEnv:
+			v.Category = tfe.CategoryEnv
+		case FlagTerraform:
+			v.Category = tfe.CategoryTerraform
+		default:
+			return v, fmt.Errorf("invalid flag %q: must be one of %s, %s, %s or %s", flag, FlagSensitive, FlagHCL, FlagEnv, FlagTerraform)
+		}
+	}
+	if v.HCL && v.Category == tfe.CategoryEnv {
+		return v, fmt.Errorf("environment variable %q cannot be HCL", v.Key)
+	}
+	return v, nil
+}
+
+// annotationRe matches the annotation comments, like "# tfe: sensitive,env".
+var annotationRe = regexp.MustCompile(`^\s*(?:#|//)\s*tfe:\s*(.*)$`)
+
+// annotations maps the line numbers of the variables to the annotation comments right
+// above them.
+type annotations map[int]annotation
+
+// annotation represents the flags of an annotation comment, and its line number.
+type annotation struct {
+	line  int
+	flags []string
 }
 
-// SimpleParseVarFile parses a var file and returns a list of HCLVariables.
-func SimpleParseVarFile(varFile string) []HCLVariable {
-	re := regexp.MustCompile(`(?im)^([a-z0-9_]*)\s*=\s*`)
-	matches := re.FindAllStringSubmatch(varFile, -1)
-	splits := re.Split(varFile, -1)
-	HCLVariables := []HCLVariable{}
-	for i, match := range matches {
-		v := HCLVariable{
-			key:   match[1],
-			value: splits[i+1],
+// take returns the flags of the annotation of a variable, and marks it as attached.
+func (a annotations) take(line int) []string {
+	flags := a[line].flags
+	delete(a, line)
+	return flags
+}
+
+// check returns an error for the first annotation which is not attached to a variable,
+// like an annotation inside or after a multi-line value.
+func (a annotations) check(filename string) error {
+	first := 0
+	for _, an := range a {
+		if first == 0 || an.line < first {
+			first = an.line
+		}
+	}
+	if first > 0 {
+		return fmt.Errorf("%s:%d: the annotation is not followed by a variable", filename, first)
+	}
+	return nil
+}
+
+// parseAnnotations maps the line numbers of the variables to the flags of the annotation
+// comments right above them.
+func parseAnnotations(data []byte, filename string) (annotations, error) {
+	result := annotations{}
+	pending := annotation{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if m := annotationRe.FindStringSubmatch(text); m != nil {
+			if pending.line == 0 {
+				pending.line = line
+			}
+			pending.flags = append(pending.flags, splitFlags(m[1])...)
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "//") {
+			continue
+		}
+		if pending.line > 0 {
+			result[line] = pending
+			pending = annotation{}
+		}
+	}
+	if pending.line > 0 {
+		return nil, fmt.Errorf("%s:%d: the annotation is not followed by a variable", filename, pending.line)
+	}
+	return result, scanner.Err()
+}
+
+// isHCLScalar reports whether a value is a number or a boolean HCL literal.
+func isHCLScalar(value string) bool {
+	expr, diags := hclsyntax.ParseExpression([]byte(value), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return false
+	}
+	if negation, ok := expr.(*hclsyntax.UnaryOpExpr); ok && negation.Op == hclsyntax.OpNegate {
+		expr = negation.Val
+	}
+	literal, ok := expr.(*hclsyntax.LiteralValueExpr)
+	if !ok {
+		return false
+	}
+	return literal.Val.Type() == cty.Number || literal.Val.Type() == cty.Bool
+}
+
+// splitFlags splits a comma separated list of flags.
+func splitFlags(s string) []string {
+	flags := []string{}
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+// variableRule associates flags to the variables whose key matches a pattern.
+type variableRule struct {
+	pattern string
+	flags   []string
+}
+
+// VariableMapping marks variables as sensitive, HCL, or as environment or Terraform variables, by key.
+type VariableMapping struct {
+	rules []variableRule
+}
+
+// ReadVariableMapping reads a mapping file.
+func ReadVariableMapping(mappingFile string) (*VariableMapping, error) {
+	fileContent, err := ioutil.ReadFile(mappingFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the file %q: %s", mappingFile, err)
+	}
+
+	return ParseVariableMapping(fileContent, mappingFile)
+}
+
+// ParseVariableMapping parses the content of a mapping file, made of "PATTERN FLAG[,FLAG]"
+// lines, like "db_* sensitive". The patterns use the syntax of path.Match, and the
+// comments start with "#".
+func ParseVariableMapping(data []byte, filename string) (*VariableMapping, error) {
+	m := &VariableMapping{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: invalid line: the format must be \"PATTERN FLAG[,FLAG]\"", filename, line)
+		}
+		if _, err := path.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %s", filename, line, fields[0], err)
+		}
+		rule := variableRule{pattern: fields[0], flags: splitFlags(fields[1])}
+		if _, err := ApplyVariableFlags(Variable{}, rule.flags); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", filename, line, err)
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, scanner.Err()
+}
+
+// Apply applies the flags of every matching rule to the variables.
+func (m *VariableMapping) Apply(vars []Variable) ([]Variable, error) {
+	results := []Variable{}
+	for _, v := range vars {
+		for _, rule := range m.rules {
+			if matched, _ := path.Match(rule.pattern, v.Key); !matched {
+				continue
+			}
+			var err error
+			if v, err = ApplyVariableFlags(v, rule.flags); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// closingQuote returns the index of the unescaped closing quote, or -1.
+func closingQuote(s, quote string) int {
+	for i := 0; i < len(s); i++ {
+		if quote == `"` && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// dotenvUnescape replaces the escape sequences of a double quoted dotenv value.
+func dotenvUnescape(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, "$")
+	return r.Replace(s)
+}
+
+// jsonVariable converts a JSON value to a variable.
+func jsonVariable(key string, raw []byte) (Variable, error) {
+	ty, err := ctyjson.ImpliedType(raw)
+	if err != nil {
+		return Variable{}, fmt.Errorf("invalid value for %q: %s", key, err)
+	}
+	value, err := ctyjson.Unmarshal(raw, ty)
+	if err != nil {
+		return Variable{}, fmt.Errorf("invalid value for %q: %s", key, err)
+	}
+	return ctyVariable(key, value)
+}
+
+// ctyVariable converts a value to a variable.
+func ctyVariable(key string, value cty.Value) (Variable, error) {
+	if !value.IsWhollyKnown() {
+		return Variable{}, fmt.Errorf("the value of %q is unknown", key)
+	}
+	if value.IsNull() {
+		return Variable{Key: key, Value: "null", HCL: true}, nil
+	}
+
+	switch value.Type() {
+	case cty.String:
+		return Variable{Key: key, Value: value.AsString()}, nil
+	case cty.Number:
+		return Variable{Key: key, Value: value.AsBigFloat().Text('f', -1)}, nil
+	case cty.Bool:
+		if value.True() {
+			return Variable{Key: key, Value: "true"}, nil
+		}
+		return Variable{Key: key, Value: "false"}, nil
+	}
+
+	// Keep the complex values as canonical HCL.
+	tokens := hclwrite.TokensForValue(value)
+	return Variable{Key: key, Value: strings.TrimSpace(string(hclwrite.Format(tokens.Bytes()))), HCL: true}, nil
+}
+
+// diagnosticsError converts HCL diagnostics to an error reporting the file and the line of each of them.
+func diagnosticsError(diags hcl.Diagnostics) error {
+	messages := []string{}
+	for _, d := range diags {
+		if d.Severity != hcl.DiagError {
+			continue
+		}
+		message := d.Summary
+		if d.Detail != "" {
+			message = fmt.Sprintf("%s: %s", d.Summary, d.Detail)
+		}
+		if d.Subject != nil {
+			message = fmt.Sprintf("%s:%d: %s", d.Subject.Filename, d.Subject.Start.Line, message)
 		}
-		HCLVariables = append(HCLVariables, v)
+		messages = append(messages, message)
 	}
-	return HCLVariables
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
 }
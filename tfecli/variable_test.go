@@ -1,22 +1,191 @@
 package tfecli
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
 )
 
 func TestParseToHCLVariables(t *testing.T) {
 	testcases := []struct {
 		hclVar string
-		want   HCLVariable
+		want   []Variable
+	}{
+		{`stringvar = "expectedValue"`, []Variable{{Key: "stringvar", Value: "expectedValue"}}},
+		{"stringvar = \"expectedValue\"   \n", []Variable{{Key: "stringvar", Value: "expectedValue"}}},
+		{"a = 1 # comment\n# b = 2\nc = true\n", []Variable{{Key: "a", Value: "1"}, {Key: "c", Value: "true"}}},
+		{"cidr = \"10.0.0.0/16\"\n// comment\n/* block\ncomment */\nratio = 0.5\n", []Variable{{Key: "cidr", Value: "10.0.0.0/16"}, {Key: "ratio", Value: "0.5"}}},
+		{"motd = <<EOT\nhello\nworld\nEOT\n", []Variable{{Key: "motd", Value: "hello\nworld\n"}}},
+		{"zones = [\n  \"a\",\n  \"b\",\n]\n", []Variable{{Key: "zones", Value: `["a", "b"]`, HCL: true}}},
+		{"tags = {\n  team = \"ops\"\n  env  = \"prod\"\n}\n", []Variable{{Key: "tags", Value: "{\n  env  = \"prod\"\n  team = \"ops\"\n}", HCL: true}}},
+		{`quoted = "say \"hi\""`, []Variable{{Key: "quoted", Value: `say "hi"`}}},
+		{`nothing = null`, []Variable{{Key: "nothing", Value: "null", HCL: true}}},
+	}
+	for _, tc := range testcases {
+		got, err := ParseVars([]byte(tc.hclVar), "test.tfvars")
+		if err != nil {
+			t.Errorf("Cannot parse %q: %s.", tc.hclVar, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect parsing got: %q, want: %q.", got, tc.want)
+		}
+	}
+}
+
+func TestParseVarsErrors(t *testing.T) {
+	testcases := []struct {
+		hclVar string
+		want   string
+	}{
+		{"a = 1\nstringvar = expectedValue\n", "test.tfvars:2:"},
+		{"a = 1\n\nb = upper(\"x\")\n", "test.tfvars:3:"},
+		{"a = \"${var.b}\"\n", "test.tfvars:1:"},
+		{"a = \n", "test.tfvars:1:"},
+		{"block {\n}\n", "test.tfvars:1:"},
+	}
+	for _, tc := range testcases {
+		_, err := ParseVars([]byte(tc.hclVar), "test.tfvars")
+		if err == nil {
+			t.Errorf("Parsing %q must fail.", tc.hclVar)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("Incorrect error for %q got: %q, want prefix: %q.", tc.hclVar, err, tc.want)
+		}
+	}
+}
+
+func TestParseJSONVars(t *testing.T) {
+	data := `{"region": "us-east-1", "count": 3, "enabled": false, "zones": ["a", "b"], "tags": {"team": "ops"}}`
+	want := []Variable{
+		{Key: "region", Value: "us-east-1"},
+		{Key: "count", Value: "3"},
+		{Key: "enabled", Value: "false"},
+		{Key: "zones", Value: `["a", "b"]`, HCL: true},
+		{Key: "tags", Value: "{\n  team = \"ops\"\n}", HCL: true},
+	}
+	got, err := ParseJSONVars([]byte(data), "test.tfvars.json")
+	if err != nil {
+		t.Fatalf("Cannot parse the variables: %s.", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect parsing got: %q, want: %q.", got, want)
+	}
+
+	for _, invalid := range []string{`["a"]`, `{"a": }`, `{"a": 1`} {
+		if _, err := ParseJSONVars([]byte(invalid), "test.tfvars.json"); err == nil {
+			t.Errorf("Parsing %q must fail.", invalid)
+		}
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	testcases := []struct {
+		env  string
+		want []Variable
 	}{
-		{`stringvar = expectedValue`, HCLVariable{key: "stringvar", value: "expectedValue"}},
+		{"A=1\n", []Variable{{Key: "A", Value: "1"}}},
+		{"# comment\n\nexport A=value # inline comment\n", []Variable{{Key: "A", Value: "value"}}},
+		{`A="say \"hi\"\n$HOME \$HOME"`, []Variable{{Key: "A", Value: "say \"hi\"\n$HOME $HOME"}}},
+		{`A='no \n escape' # comment`, []Variable{{Key: "A", Value: `no \n escape`}}},
+		{"A=\"first\nsecond\"\nB=2\n", []Variable{{Key: "A", Value: "first\nsecond"}, {Key: "B", Value: "2"}}},
+		{"A=\nB=a#b\n", []Variable{{Key: "A", Value: ""}, {Key: "B", Value: "a#b"}}},
 	}
 	for _, tc := range testcases {
-		gots := SimpleParseVarFile(tc.hclVar)
-		for _, got := range gots {
-			if got != tc.want {
-				t.Errorf("Incorrect parsing got: %s, want: %s.", got, tc.want)
-			}
+		got, err := ParseDotenv([]byte(tc.env), ".env")
+		if err != nil {
+			t.Errorf("Cannot parse %q: %s.", tc.env, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Incorrect parsing of %q got: %q, want: %q.", tc.env, got, tc.want)
+		}
+	}
+
+	errors := []struct {
+		env  string
+		want string
+	}{
+		{"A=1\nnot a variable\n", ".env:2:"},
+		{"A=1\n1A=2\n", ".env:2:"},
+		{"A=1\nB=\"unterminated\n", ".env:2:"},
+		{"A='quoted' trailing\n", ".env:1:"},
+	}
+	for _, tc := range errors {
+		_, err := ParseDotenv([]byte(tc.env), ".env")
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("Incorrect error for %q got: %v, want prefix: %q.", tc.env, err, tc.want)
+		}
+	}
+}
+
+func TestVariableAnnotations(t *testing.T) {
+	hclVars := "region = \"us-east-1\"\n\n# tfe: sensitive\ndb_password = \"secret\"\n# tfe: env,sensitive\n# Used by the provider.\nAWS_SECRET_ACCESS_KEY = \"key\"\n# tfe: hcl\nport = 5432\n# tfe: hcl\nname = \"db\"\n"
+	got, err := ParseVars([]byte(hclVars), "test.tfvars")
+	if err != nil {
+		t.Fatalf("Cannot parse the variables: %s.", err)
+	}
+	want := []Variable{
+		{Key: "region", Value: "us-east-1"},
+		{Key: "db_password", Value: "secret", Sensitive: true},
+		{Key: "AWS_SECRET_ACCESS_KEY", Value: "key", Category: tfe.CategoryEnv, Sensitive: true},
+		{Key: "port", Value: "5432", HCL: true},
+		{Key: "name", Value: `"db"`, HCL: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect parsing got: %+v, want: %+v.", got, want)
+	}
+
+	if _, err := ParseDotenv([]byte("A=\"1\n# tfe: sensitive\n2\"\n"), ".env"); err == nil {
+		t.Errorf("An annotation inside a multi-line value must be reported.")
+	}
+	got, err = ParseDotenv([]byte("A=1\n# tfe: sensitive\nB=\"2\"\n"), ".env")
+	if err != nil {
+		t.Fatalf("Cannot parse the variables: %s.", err)
+	}
+	want = []Variable{{Key: "A", Value: "1"}, {Key: "B", Value: "2", Sensitive: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect parsing got: %+v, want: %+v.", got, want)
+	}
+
+	invalidFiles := []string{
+		"# tfe: secret\na = 1\n",
+		"a = 1\n# tfe: sensitive\n",
+		"# tfe: env\ntags = { a = 1 }\n",
+		"tags = {\n  # tfe: sensitive\n  a = 1\n}\n",
+		"motd = <<EOT\n# tfe: sensitive\nhello\nEOT\n",
+	}
+	for _, invalid := range invalidFiles {
+		if _, err := ParseVars([]byte(invalid), "test.tfvars"); err == nil {
+			t.Errorf("Parsing %q must fail.", invalid)
+		}
+	}
+}
+
+func TestVariableMapping(t *testing.T) {
+	m, err := ParseVariableMapping([]byte("# Secrets.\ndb_*      sensitive\nAWS_*     env,sensitive\n"), "mapping")
+	if err != nil {
+		t.Fatalf("Cannot parse the mapping: %s.", err)
+	}
+	got, err := m.Apply([]Variable{{Key: "region", Value: "us-east-1"}, {Key: "db_password", Value: "secret"}, {Key: "AWS_REGION", Value: "us-east-1"}})
+	if err != nil {
+		t.Fatalf("Cannot apply the mapping: %s.", err)
+	}
+	want := []Variable{
+		{Key: "region", Value: "us-east-1"},
+		{Key: "db_password", Value: "secret", Sensitive: true},
+		{Key: "AWS_REGION", Value: "us-east-1", Category: tfe.CategoryEnv, Sensitive: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect mapping got: %+v, want: %+v.", got, want)
+	}
+
+	for _, invalid := range []string{"db_password\n", "db_* secret\n", "[ sensitive\n"} {
+		if _, err := ParseVariableMapping([]byte(invalid), "mapping"); err == nil {
+			t.Errorf("Parsing %q must fail.", invalid)
 		}
 	}
 }
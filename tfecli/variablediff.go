@@ -0,0 +1,183 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// List the statuses of the differences between variables.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+	DiffUnknown = "unknown"
+)
+
+// DiffValue represents a side of a difference between variables. The value of a
+// sensitive variable is unknown, and nil.
+type DiffValue struct {
+	Value     *string `json:"value"`
+	HCL       bool    `json:"hcl"`
+	Sensitive bool    `json:"sensitive"`
+}
+
+// VariableDiff represents a difference between two variables with the same key and
+// category. The changes list the differing value, hcl and sensitive fields.
+type VariableDiff struct {
+	Key      string           `json:"key"`
+	Category tfe.CategoryType `json:"category"`
+	Status   string           `json:"status"`
+	Changes  []string         `json:"changes,omitempty"`
+	Old      *DiffValue       `json:"old,omitempty"`
+	New      *DiffValue       `json:"new,omitempty"`
+}
+
+// DiffVariables compares two sets of variables, identified by their key and category.
+// The values of the sensitive variables are unknown: when their flags are the same,
+// their difference is reported as unknown. The differences are sorted by key and
+// category, and the identical variables are omitted.
+func DiffVariables(before, after []Variable) []VariableDiff {
+	id := func(v Variable) string { return string(v.Category) + "/" + v.Key }
+	index := func(vars []Variable) map[string]Variable {
+		m := map[string]Variable{}
+		for _, v := range vars {
+			m[id(v)] = v
+		}
+		return m
+	}
+	oldVars, newVars := index(before), index(after)
+
+	diffs := []VariableDiff{}
+	for k, o := range oldVars {
+		n, ok := newVars[k]
+		if !ok {
+			diffs = append(diffs, VariableDiff{Key: o.Key, Category: o.Category, Status: DiffRemoved, Old: diffValue(o)})
+			continue
+		}
+		changes := []string{}
+		if o.Sensitive || n.Sensitive {
+			if o.Sensitive != n.Sensitive {
+				changes = append(changes, "sensitive")
+			}
+		} else if o.Value != n.Value {
+			changes = append(changes, "value")
+		}
+		if o.HCL != n.HCL {
+			changes = append(changes, "hcl")
+		}
+
+		d := VariableDiff{Key: o.Key, Category: o.Category, Status: DiffChanged, Changes: changes, Old: diffValue(o), New: diffValue(n)}
+		switch {
+		case len(changes) > 0:
+		case o.Sensitive:
+			d.Status = DiffUnknown
+		default:
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+	for k, n := range newVars {
+		if _, ok := oldVars[k]; !ok {
+			diffs = append(diffs, VariableDiff{Key: n.Key, Category: n.Category, Status: DiffAdded, New: diffValue(n)})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Key != diffs[j].Key {
+			return diffs[i].Key < diffs[j].Key
+		}
+		return diffs[i].Category < diffs[j].Category
+	})
+	return diffs
+}
+
+// diffValue returns the side of a difference of a variable.
+func diffValue(v Variable) *DiffValue {
+	d := &DiffValue{HCL: v.HCL, Sensitive: v.Sensitive}
+	if !v.Sensitive {
+		value := v.Value
+		d.Value = &value
+	}
+	return d
+}
+
+// List the ANSI colors of the differences.
+const (
+	colorBold   = "\033[1m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+// WriteVariableDiff writes the differences between two sets of variables, named after
+// their source, as JSON or as a colored unified diff.
+func WriteVariableDiff(w io.Writer, diffs []VariableDiff, oldName, newName, format string, color bool) error {
+	b := &strings.Builder{}
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(struct {
+			Old         string         `json:"old"`
+			New         string         `json:"new"`
+			Differences []VariableDiff `json:"differences"`
+		}{oldName, newName, diffs}, "", "  ")
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	case "text":
+		paint := func(c, s string) string {
+			if !color {
+				return s
+			}
+			return c + s + colorReset
+		}
+		b.WriteString(paint(colorBold, "--- "+oldName) + "\n")
+		b.WriteString(paint(colorBold, "+++ "+newName) + "\n")
+		counts := map[string]int{}
+		for _, d := range diffs {
+			counts[d.Status]++
+			comment := ""
+			if len(d.Changes) > 0 {
+				comment = "  # " + strings.Join(d.Changes, ", ")
+			}
+			switch d.Status {
+			case DiffRemoved:
+				b.WriteString(paint(colorRed, diffLines("-", d.Key, d.Category, d.Old, "")))
+			case DiffAdded:
+				b.WriteString(paint(colorGreen, diffLines("+", d.Key, d.Category, d.New, "")))
+			case DiffChanged:
+				b.WriteString(paint(colorRed, diffLines("-", d.Key, d.Category, d.Old, "")))
+				b.WriteString(paint(colorGreen, diffLines("+", d.Key, d.Category, d.New, comment)))
+			case DiffUnknown:
+				b.WriteString(paint(colorYellow, diffLines(" ", d.Key, d.Category, d.New, "  # value unknown")))
+			}
+		}
+		fmt.Fprintf(b, "\n%d added, %d removed, %d changed, %d unknown.\n", counts[DiffAdded], counts[DiffRemoved], counts[DiffChanged], counts[DiffUnknown])
+	default:
+		return fmt.Errorf("invalid format %q: must be text or json", format)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// diffLines renders a side of a difference, prefixing each of its lines.
+func diffLines(prefix, key string, category tfe.CategoryType, v *DiffValue, comment string) string {
+	value := "(sensitive)"
+	switch {
+	case v.Value == nil:
+	case v.HCL:
+		value = *v.Value
+	default:
+		value = HCLString(*v.Value)
+	}
+	lines := strings.Split(fmt.Sprintf("%s %s = %s%s", category, key, value, comment), "\n")
+	return prefix + strings.Join(lines, "\n"+prefix) + "\n"
+}
@@ -0,0 +1,71 @@
+package tfecli
+
+import (
+	"strings"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestDiffVariables(t *testing.T) {
+	before := []Variable{
+		{Key: "region", Value: "us-east-1", Category: tfe.CategoryTerraform},
+		{Key: "zones", Value: `["a"]`, Category: tfe.CategoryTerraform, HCL: true},
+		{Key: "db_password", Category: tfe.CategoryTerraform, Sensitive: true},
+		{Key: "token", Category: tfe.CategoryTerraform, Sensitive: true},
+		{Key: "stale", Value: "1", Category: tfe.CategoryTerraform},
+		{Key: "same", Value: "1", Category: tfe.CategoryEnv},
+	}
+	after := []Variable{
+		{Key: "region", Value: "us-west-2", Category: tfe.CategoryTerraform},
+		{Key: "zones", Value: `["a"]`, Category: tfe.CategoryTerraform},
+		{Key: "db_password", Value: "secret", Category: tfe.CategoryTerraform, Sensitive: true},
+		{Key: "token", Value: "public", Category: tfe.CategoryTerraform},
+		{Key: "added", Value: "2", Category: tfe.CategoryTerraform},
+		{Key: "same", Value: "1", Category: tfe.CategoryEnv},
+	}
+	diffs := DiffVariables(before, after)
+
+	want := []struct {
+		key     string
+		status  string
+		changes string
+	}{
+		{"added", DiffAdded, ""},
+		{"db_password", DiffUnknown, ""},
+		{"region", DiffChanged, "value"},
+		{"stale", DiffRemoved, ""},
+		{"token", DiffChanged, "sensitive"},
+		{"zones", DiffChanged, "hcl"},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("Incorrect number of differences got: %d (%+v), want: %d.", len(diffs), diffs, len(want))
+	}
+	for i, w := range want {
+		if d := diffs[i]; d.Key != w.key || d.Status != w.status || strings.Join(d.Changes, ",") != w.changes {
+			t.Errorf("Incorrect difference %d got: %s %s %v, want: %s %s %s.", i, d.Key, d.Status, d.Changes, w.key, w.status, w.changes)
+		}
+	}
+	if diffs[1].New.Value != nil {
+		t.Errorf("The value of a sensitive variable must be unknown.")
+	}
+
+	testcases := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{"--- ws-a\n+++ ws-b\n", "-terraform region = \"us-east-1\"\n+terraform region = \"us-west-2\"  # value\n", " terraform db_password = (sensitive)  # value unknown\n", "1 added, 1 removed, 3 changed, 1 unknown."}},
+		{"json", []string{`"old": "ws-a"`, `"status": "unknown"`, `"value": null`}},
+	}
+	for _, tc := range testcases {
+		b := &strings.Builder{}
+		if err := WriteVariableDiff(b, diffs, "ws-a", "ws-b", tc.format, false); err != nil {
+			t.Fatalf("Cannot write the %s diff: %s.", tc.format, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("Missing %q in the %s diff:\n%s", want, tc.format, b.String())
+			}
+		}
+	}
+}
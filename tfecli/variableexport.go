@@ -0,0 +1,175 @@
+package tfecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// VariableFormats lists the formats supported by WriteVariables, with the extension of their files.
+var VariableFormats = map[string]string{
+	"tfvars":      "tfvars",
+	"tfvars-json": "tfvars.json",
+	"dotenv":      "env",
+	"json":        "json",
+}
+
+// exportedVariable represents a variable exported as JSON, the value of a sensitive
+// variable being null.
+type exportedVariable struct {
+	Key       string           `json:"key"`
+	Value     *string          `json:"value"`
+	Category  tfe.CategoryType `json:"category"`
+	HCL       bool             `json:"hcl"`
+	Sensitive bool             `json:"sensitive"`
+}
+
+// WriteVariables writes variables of the same category, sorted by key, in a format:
+//
+//   - tfvars: HCL assignments, the HCL values being written verbatim.
+//   - tfvars-json: a JSON object, the HCL values being converted to JSON.
+//   - dotenv: double quoted values, the Terraform variables being prefixed with TF_VAR_.
+//   - json: a list of the variables with their category and flags.
+//
+// The values of the sensitive variables cannot be read: they are written as comments
+// in the tfvars and dotenv formats, as null in the json format, and left out of the
+// tfvars-json format, which has no comments.
+func WriteVariables(w io.Writer, vars []Variable, format string) error {
+	sorted := append([]Variable{}, vars...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	b := &strings.Builder{}
+
+	switch format {
+	case "tfvars":
+		for _, v := range sorted {
+			switch {
+			case v.Sensitive:
+				fmt.Fprintf(b, "# %s = <sensitive>\n", v.Key)
+			case v.HCL:
+				fmt.Fprintf(b, "%s = %s\n", v.Key, v.Value)
+			default:
+				fmt.Fprintf(b, "%s = %s\n", v.Key, HCLString(v.Value))
+			}
+		}
+	case "dotenv":
+		for _, v := range sorted {
+			key := v.Key
+			if v.Category == tfe.CategoryTerraform {
+				key = "TF_VAR_" + key
+			}
+			if v.Sensitive {
+				fmt.Fprintf(b, "# %s=<sensitive>\n", key)
+				continue
+			}
+			fmt.Fprintf(b, "%s=%s\n", key, DotenvQuote(v.Value))
+		}
+	case "tfvars-json", "json":
+		data, err := json.MarshalIndent(variablesDocument(sorted, format), "", "  ")
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	default:
+		return fmt.Errorf("invalid format %q: must be tfvars, tfvars-json, dotenv or json", format)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteAllVariables writes variables of both categories to a single output, the
+// Terraform variables first. The dotenv format separates them with a comment, and the
+// json format writes an object indexed by category. The tfvars formats cannot hold
+// environment variables: only the Terraform variables are written.
+func WriteAllVariables(w io.Writer, vars []Variable, format string) error {
+	categories := []struct {
+		category tfe.CategoryType
+		title    string
+	}{
+		{tfe.CategoryTerraform, "Terraform variables"},
+		{tfe.CategoryEnv, "Environment variables"},
+	}
+	byCategory := map[tfe.CategoryType][]Variable{}
+	for _, v := range vars {
+		byCategory[v.Category] = append(byCategory[v.Category], v)
+	}
+
+	switch format {
+	case "tfvars", "tfvars-json":
+		return WriteVariables(w, byCategory[tfe.CategoryTerraform], format)
+	case "json":
+		docs := map[tfe.CategoryType]interface{}{}
+		for _, c := range categories {
+			sorted := append([]Variable{}, byCategory[c.category]...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+			docs[c.category] = variablesDocument(sorted, format)
+		}
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	for i, c := range categories {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		fmt.Fprintf(w, "# %s.\n", c.title)
+		if err := WriteVariables(w, byCategory[c.category], format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// variablesDocument converts sorted variables to a tfvars-json or json document.
+func variablesDocument(vars []Variable, format string) interface{} {
+	if format == "json" {
+		exported := []exportedVariable{}
+		for _, v := range vars {
+			e := exportedVariable{Key: v.Key, Category: v.Category, HCL: v.HCL, Sensitive: v.Sensitive}
+			if !v.Sensitive {
+				value := v.Value
+				e.Value = &value
+			}
+			exported = append(exported, e)
+		}
+		return exported
+	}
+
+	values := map[string]json.RawMessage{}
+	for _, v := range vars {
+		if v.Sensitive {
+			continue
+		}
+		values[v.Key] = variableJSON(v)
+	}
+	return values
+}
+
+// variableJSON converts the value of a variable to JSON. The HCL values which are not
+// literals are kept as strings.
+func variableJSON(v Variable) json.RawMessage {
+	if v.HCL {
+		expr, diags := hclsyntax.ParseExpression([]byte(v.Value), v.Key, hcl.Pos{Line: 1, Column: 1})
+		if !diags.HasErrors() {
+			if value, diags := expr.Value(nil); !diags.HasErrors() && value.IsWhollyKnown() {
+				if data, err := ctyjson.Marshal(value, value.Type()); err == nil {
+					return data
+				}
+			}
+		}
+	}
+	data, _ := json.Marshal(v.Value)
+	return data
+}
@@ -0,0 +1,68 @@
+package tfecli
+
+import (
+	"strings"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestWriteVariables(t *testing.T) {
+	vars := []Variable{
+		{Key: "region", Value: "us-east-1", Category: tfe.CategoryTerraform},
+		{Key: "motd", Value: "say \"hi\"\n${name}", Category: tfe.CategoryTerraform},
+		{Key: "tags", Value: "{\n  team = \"ops\"\n}", Category: tfe.CategoryTerraform, HCL: true},
+		{Key: "db_password", Category: tfe.CategoryTerraform, Sensitive: true},
+	}
+	testcases := []struct {
+		format string
+		want   string
+	}{
+		{"tfvars", "# db_password = <sensitive>\nmotd = \"say \\\"hi\\\"\\n$${name}\"\nregion = \"us-east-1\"\ntags = {\n  team = \"ops\"\n}\n"},
+		{"dotenv", "# TF_VAR_db_password=<sensitive>\nTF_VAR_motd=\"say \\\"hi\\\"\\n${name}\"\nTF_VAR_region=\"us-east-1\"\nTF_VAR_tags=\"{\\n  team = \\\"ops\\\"\\n}\"\n"},
+		{"tfvars-json", "{\n  \"motd\": \"say \\\"hi\\\"\\n${name}\",\n  \"region\": \"us-east-1\",\n  \"tags\": {\n    \"team\": \"ops\"\n  }\n}\n"},
+	}
+	for _, tc := range testcases {
+		b := &strings.Builder{}
+		if err := WriteVariables(b, vars, tc.format); err != nil {
+			t.Fatalf("Cannot write the %s variables: %s.", tc.format, err)
+		}
+		if b.String() != tc.want {
+			t.Errorf("Incorrect %s variables got:\n%s\nwant:\n%s", tc.format, b.String(), tc.want)
+		}
+	}
+
+	// The tfvars are parsed back to the same variables.
+	b := &strings.Builder{}
+	WriteVariables(b, vars[:3], "tfvars")
+	parsed, err := ParseVars([]byte(b.String()), "export.tfvars")
+	if err != nil {
+		t.Fatalf("Cannot parse the exported variables: %s.", err)
+	}
+	if len(parsed) != 3 || parsed[0].Value != vars[1].Value || parsed[2].Value != vars[2].Value || !parsed[2].HCL {
+		t.Errorf("Incorrect exported variables got: %+v.", parsed)
+	}
+
+	b = &strings.Builder{}
+	if err := WriteAllVariables(b, append(vars, Variable{Key: "AWS_REGION", Value: "us-east-1", Category: tfe.CategoryEnv}), "json"); err != nil {
+		t.Fatalf("Cannot write the variables: %s.", err)
+	}
+	for _, want := range []string{`"env": [`, `"key": "AWS_REGION"`, `"value": null`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Missing %q in the variables:\n%s", want, b.String())
+		}
+	}
+
+	// The tfvars formats only hold the Terraform variables.
+	b = &strings.Builder{}
+	if err := WriteAllVariables(b, append(vars, Variable{Key: "AWS_REGION", Value: "us-east-1", Category: tfe.CategoryEnv}), "tfvars"); err != nil {
+		t.Fatalf("Cannot write the variables: %s.", err)
+	}
+	if b.String() != testcases[0].want {
+		t.Errorf("Incorrect tfvars variables got:\n%s\nwant:\n%s", b.String(), testcases[0].want)
+	}
+
+	if err := WriteVariables(&strings.Builder{}, vars, "yaml"); err == nil {
+		t.Errorf("An invalid format must be rejected.")
+	}
+}
@@ -0,0 +1,206 @@
+package tfecli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// List the actions syncing the variables of a workspace.
+const (
+	SyncCreate  = "create"
+	SyncUpdate  = "update"
+	SyncReplace = "replace"
+	SyncDelete  = "delete"
+	SyncKeep    = "keep"
+)
+
+// VariableChange represents a change syncing a variable of a workspace. The current
+// variable is nil for a creation, and the desired one is nil for a deletion.
+type VariableChange struct {
+	Action   string
+	Key      string
+	Category tfe.CategoryType
+	Current  *tfe.Variable
+	Desired  *tfe.VariableCreateOptions
+	Reason   string
+}
+
+// PlanVariableSync computes the changes making the variables of a workspace match the
+// desired ones, the variables being identified by their key and category. The current
+// variables missing from the desired ones are deleted, unless their key matches one of
+// the keep patterns. The sensitive variables are always updated, since their values
+// cannot be compared, unless skipSensitive is true and their HCL flag is unchanged. They
+// are replaced to become non-sensitive. The changes are sorted by key and category, and
+// the unchanged variables are omitted.
+func PlanVariableSync(current []*tfe.Variable, desired []tfe.VariableCreateOptions, keep []string, skipSensitive bool) ([]VariableChange, error) {
+	for _, pattern := range keep {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid keep pattern %q: %s", pattern, err)
+		}
+	}
+
+	// Index the desired variables, the last one winning.
+	wanted := map[string]*tfe.VariableCreateOptions{}
+	for i := range desired {
+		d := &desired[i]
+		wanted[VariableID(*d.Key, *d.Category)] = d
+	}
+
+	changes := []VariableChange{}
+	seen := map[string]bool{}
+	for _, c := range current {
+		seen[VariableID(c.Key, c.Category)] = true
+		d, ok := wanted[VariableID(c.Key, c.Category)]
+		if !ok {
+			change := VariableChange{Action: SyncDelete, Key: c.Key, Category: c.Category, Current: c, Reason: "missing from the sources"}
+			for _, pattern := range keep {
+				if matched, _ := path.Match(pattern, c.Key); matched {
+					change.Action, change.Reason = SyncKeep, fmt.Sprintf("matches %q", pattern)
+					break
+				}
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		change := VariableChange{Key: c.Key, Category: c.Category, Current: c, Desired: d}
+		hcl, sensitive := d.HCL != nil && *d.HCL, d.Sensitive != nil && *d.Sensitive
+		switch {
+		case c.Sensitive && !sensitive:
+			change.Action, change.Reason = SyncReplace, "cannot become non-sensitive"
+		case c.Sensitive && c.HCL != hcl:
+			change.Action, change.Reason = SyncUpdate, "hcl"
+		case c.Sensitive && skipSensitive:
+			continue
+		case c.Sensitive:
+			change.Action, change.Reason = SyncUpdate, "sensitive value"
+		default:
+			diffs := []string{}
+			if c.Value != *d.Value {
+				diffs = append(diffs, "value")
+			}
+			if c.HCL != hcl {
+				diffs = append(diffs, "hcl")
+			}
+			if sensitive {
+				diffs = append(diffs, "sensitive")
+			}
+			if len(diffs) == 0 {
+				continue
+			}
+			change.Action, change.Reason = SyncUpdate, strings.Join(diffs, ", ")
+		}
+		changes = append(changes, change)
+	}
+	for _, d := range wanted {
+		if !seen[VariableID(*d.Key, *d.Category)] {
+			changes = append(changes, VariableChange{Action: SyncCreate, Key: *d.Key, Category: *d.Category, Desired: d})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Category < changes[j].Category
+	})
+	return changes, nil
+}
+
+// VariableID identifies a variable of a workspace by its category and key, since an
+// environment variable and a Terraform variable can have the same key.
+func VariableID(key string, category tfe.CategoryType) string {
+	return string(category) + "/" + key
+}
+
+// SyncSummary summarizes the changes syncing the variables of a workspace.
+func SyncSummary(changes []VariableChange) string {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Action]++
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to replace, %d to delete, %d kept",
+		counts[SyncCreate], counts[SyncUpdate], counts[SyncReplace], counts[SyncDelete], counts[SyncKeep])
+}
+
+// VariableWriter creates, updates and deletes the variables of a workspace.
+type VariableWriter interface {
+	Create(options tfe.VariableCreateOptions) error
+	Update(variableID string, options tfe.VariableCreateOptions) error
+	Delete(variable *tfe.Variable) error
+}
+
+// ApplyVariableSync applies the changes syncing the variables of a workspace, at most
+// parallelism at the same time, and returns the errors of all the failed changes. The
+// replacements delete a variable before creating it again: they are applied last, and
+// only if all the other changes succeeded.
+func ApplyVariableSync(w VariableWriter, changes []VariableChange, parallelism int) []error {
+	others, replacements := []VariableChange{}, []VariableChange{}
+	for _, c := range changes {
+		switch c.Action {
+		case SyncKeep:
+		case SyncReplace:
+			replacements = append(replacements, c)
+		default:
+			others = append(others, c)
+		}
+	}
+	if errs := applyVariableChanges(w, others, parallelism); len(errs) > 0 {
+		if len(replacements) > 0 {
+			errs = append(errs, fmt.Errorf("%d replacements not applied", len(replacements)))
+		}
+		return errs
+	}
+	return applyVariableChanges(w, replacements, parallelism)
+}
+
+// applyVariableChanges applies changes in parallel, and returns their errors.
+func applyVariableChanges(w VariableWriter, changes []VariableChange, parallelism int) []error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := []error{}
+	sem := make(chan struct{}, parallelism)
+	for _, change := range changes {
+		change := change
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := ApplyVariableChange(w, change); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// ApplyVariableChange applies a change syncing a variable of a workspace.
+func ApplyVariableChange(w VariableWriter, change VariableChange) error {
+	switch change.Action {
+	case SyncCreate:
+		return w.Create(*change.Desired)
+	case SyncUpdate:
+		return w.Update(change.Current.ID, *change.Desired)
+	case SyncDelete:
+		if err := w.Delete(change.Current); err != nil {
+			return fmt.Errorf("cannot delete variable %q: %s", change.Key, err)
+		}
+	case SyncReplace:
+		if err := w.Delete(change.Current); err != nil {
+			return fmt.Errorf("cannot replace variable %q: %s", change.Key, err)
+		}
+		if err := w.Create(*change.Desired); err != nil {
+			return fmt.Errorf("variable %q was deleted but not created again: %s", change.Key, err)
+		}
+	}
+	return nil
+}
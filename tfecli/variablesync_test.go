@@ -0,0 +1,143 @@
+package tfecli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+func TestPlanVariableSync(t *testing.T) {
+	current := []*tfe.Variable{
+		{Key: "region", Value: "us-east-1", Category: tfe.CategoryTerraform},
+		{Key: "zones", Value: `["a"]`, Category: tfe.CategoryTerraform, HCL: true},
+		{Key: "db_password", Category: tfe.CategoryTerraform, Sensitive: true},
+		{Key: "token", Category: tfe.CategoryTerraform, Sensitive: true},
+		{Key: "stale", Value: "1", Category: tfe.CategoryTerraform},
+		{Key: "TFE_TOKEN", Category: tfe.CategoryEnv, Sensitive: true},
+		{Key: "AWS_REGION", Value: "us-east-1", Category: tfe.CategoryEnv},
+	}
+	option := func(key, value string, category tfe.CategoryType, hcl, sensitive bool) tfe.VariableCreateOptions {
+		return tfe.VariableCreateOptions{Key: tfe.String(key), Value: tfe.String(value), Category: tfe.Category(category), HCL: tfe.Bool(hcl), Sensitive: tfe.Bool(sensitive)}
+	}
+	desired := []tfe.VariableCreateOptions{
+		option("region", "us-east-1", tfe.CategoryTerraform, false, false),
+		option("zones", `["a", "b"]`, tfe.CategoryTerraform, true, false),
+		option("db_password", "secret", tfe.CategoryTerraform, false, true),
+		option("token", "public", tfe.CategoryTerraform, false, false),
+		option("AWS_REGION", "us-east-1", tfe.CategoryTerraform, false, false),
+		option("instance_type", "t3.micro", tfe.CategoryTerraform, false, false),
+	}
+	changes, err := PlanVariableSync(current, desired, []string{"TFE_*"}, false)
+	if err != nil {
+		t.Fatalf("Cannot plan the sync: %s.", err)
+	}
+
+	want := []struct {
+		action   string
+		key      string
+		category tfe.CategoryType
+	}{
+		{SyncDelete, "AWS_REGION", tfe.CategoryEnv},
+		{SyncCreate, "AWS_REGION", tfe.CategoryTerraform},
+		{SyncKeep, "TFE_TOKEN", tfe.CategoryEnv},
+		{SyncUpdate, "db_password", tfe.CategoryTerraform},
+		{SyncCreate, "instance_type", tfe.CategoryTerraform},
+		{SyncDelete, "stale", tfe.CategoryTerraform},
+		{SyncReplace, "token", tfe.CategoryTerraform},
+		{SyncUpdate, "zones", tfe.CategoryTerraform},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Incorrect number of changes got: %d (%+v), want: %d.", len(changes), changes, len(want))
+	}
+	for i, w := range want {
+		if c := changes[i]; c.Action != w.action || c.Key != w.key || c.Category != w.category {
+			t.Errorf("Incorrect change %d got: %s %s %s, want: %s %s %s.", i, c.Action, c.Category, c.Key, w.action, w.category, w.key)
+		}
+	}
+	if got, want := SyncSummary(changes), "2 to create, 2 to update, 1 to replace, 2 to delete, 1 kept"; got != want {
+		t.Errorf("Incorrect summary got: %q, want: %q.", got, want)
+	}
+
+	// The sensitive variables staying sensitive are not updated.
+	changes, err = PlanVariableSync(current, desired, []string{"TFE_*"}, true)
+	if err != nil {
+		t.Fatalf("Cannot plan the sync: %s.", err)
+	}
+	if got, want := SyncSummary(changes), "2 to create, 1 to update, 1 to replace, 2 to delete, 1 kept"; got != want {
+		t.Errorf("Incorrect summary got: %q, want: %q.", got, want)
+	}
+
+	if _, err := PlanVariableSync(current, desired, []string{"["}, false); err == nil {
+		t.Errorf("An invalid keep pattern must be rejected.")
+	}
+}
+
+// fakeVariableWriter records the operations on the variables, failing the creation of some keys.
+type fakeVariableWriter struct {
+	mu         sync.Mutex
+	operations []string
+	failCreate map[string]bool
+}
+
+func (w *fakeVariableWriter) record(operation string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.operations = append(w.operations, operation)
+}
+
+func (w *fakeVariableWriter) Create(options tfe.VariableCreateOptions) error {
+	if w.failCreate[*options.Key] {
+		return errors.New("internal error")
+	}
+	w.record("create " + *options.Key)
+	return nil
+}
+
+func (w *fakeVariableWriter) Update(variableID string, options tfe.VariableCreateOptions) error {
+	w.record("update " + variableID)
+	return nil
+}
+
+func (w *fakeVariableWriter) Delete(variable *tfe.Variable) error {
+	w.record("delete " + variable.ID)
+	return nil
+}
+
+func TestApplyVariableSync(t *testing.T) {
+	desired := func(key string) *tfe.VariableCreateOptions {
+		return &tfe.VariableCreateOptions{Key: tfe.String(key), Value: tfe.String("v"), Category: tfe.Category(tfe.CategoryTerraform)}
+	}
+	changes := []VariableChange{
+		{Action: SyncReplace, Key: "token", Current: &tfe.Variable{ID: "var-token"}, Desired: desired("token")},
+		{Action: SyncCreate, Key: "region", Desired: desired("region")},
+		{Action: SyncKeep, Key: "TFE_TOKEN", Current: &tfe.Variable{ID: "var-tfe"}},
+	}
+
+	// The replacements are applied last.
+	w := &fakeVariableWriter{}
+	if errs := ApplyVariableSync(w, changes, 1); len(errs) > 0 {
+		t.Fatalf("Cannot apply the changes: %v.", errs)
+	}
+	want := []string{"create region", "delete var-token", "create token"}
+	if !reflect.DeepEqual(w.operations, want) {
+		t.Errorf("Incorrect operations got: %v, want: %v.", w.operations, want)
+	}
+
+	// A failed replacement reports the deletion.
+	w = &fakeVariableWriter{failCreate: map[string]bool{"token": true}}
+	errs := ApplyVariableSync(w, changes, 2)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `variable "token" was deleted but not created again`) {
+		t.Errorf("Incorrect errors got: %v.", errs)
+	}
+
+	// The replacements are not applied when another change failed.
+	w = &fakeVariableWriter{failCreate: map[string]bool{"region": true}}
+	errs = ApplyVariableSync(w, changes, 2)
+	if len(errs) != 2 || len(w.operations) != 0 {
+		t.Errorf("Incorrect errors got: %v, operations: %v.", errs, w.operations)
+	}
+}